@@ -1,16 +1,31 @@
 # gcp
 export PROJECT_ID=
 export REGION=
+# optional, the Apigee environment to deploy onramped proxies to, and apikey or oauth to verify in them
+export APIGEE_ENVIRONMENT=
+export APIGEE_SECURITY=
 
 # azure
 export SERVICE_NAME=
 export RESOURCE_GROUP=
 export CLIENT_ID=
+# optional, without a secret the Cloud Run service identity is federated to the CLIENT_ID app registration
 export CLIENT_SECRET=
 export TENANT_ID=
 export SUBSCRIPTION_ID=
+# optional, set to true to export API revision history, product subscription counts and policies
+export AZURE_REVISIONS=
+export AZURE_SUBSCRIPTION_COUNTS=
+export AZURE_POLICIES=
+# optional, comma-separated subscription IDs (or all) to discover every API Management service in
+export AZURE_SUBSCRIPTIONS=
+# optional, the Azure API Center service to offramp APIs from or onramp APIs to, in the same subscription and resource group
+export AZURE_APICENTER_NAME=
 
 # aws
 export AWS_ACCESS_KEY_ID=
 export AWS_SECRET_ACCESS_KEY=
-export AWS_REGION=
\ No newline at end of file
+export AWS_REGION=
+# optional, comma-separated regions (or all) and role ARNs for multi-region and multi-account discovery
+export AWS_REGIONS=
+export AWS_ROLE_ARNS=
\ No newline at end of file
@@ -1,5 +1,5 @@
-APIGEE_PROJECT=$PROJECT_ID APIGEE_REGION=$REGION AZURE_SUBSCRIPTION_ID=$SUBSCRIPTION_ID \
+APIGEE_PROJECT=$PROJECT_ID APIGEE_REGION=$REGION APIGEE_ENVIRONMENT=$APIGEE_ENVIRONMENT APIGEE_SECURITY=$APIGEE_SECURITY AZURE_SUBSCRIPTION_ID=$SUBSCRIPTION_ID \
 AZURE_RESOURCE_GROUP=$RESOURCE_GROUP AZURE_SERVICE_NAME=$SERVICE_NAME AZURE_CLIENT_ID=$CLIENT_ID \
-AZURE_CLIENT_SECRET=$CLIENT_SECRET AZURE_TENANT_ID=$TENANT_ID AWS_ACCESS_KEY_ID=$AWS_ACCESS_KEY_ID \
-AWS_SECRET_ACCESS_KEY=$AWS_SECRET_ACCESS_KEY AWS_REGION=$AWS_REGION \
+AZURE_CLIENT_SECRET=$CLIENT_SECRET AZURE_TENANT_ID=$TENANT_ID AZURE_REVISIONS=$AZURE_REVISIONS AZURE_SUBSCRIPTION_COUNTS=$AZURE_SUBSCRIPTION_COUNTS AZURE_POLICIES=$AZURE_POLICIES AZURE_SUBSCRIPTIONS=$AZURE_SUBSCRIPTIONS AZURE_APICENTER_NAME=$AZURE_APICENTER_NAME AWS_ACCESS_KEY_ID=$AWS_ACCESS_KEY_ID \
+AWS_SECRET_ACCESS_KEY=$AWS_SECRET_ACCESS_KEY AWS_REGION=$AWS_REGION AWS_REGIONS=$AWS_REGIONS AWS_ROLE_ARNS=$AWS_ROLE_ARNS \
 go run . ws start
\ No newline at end of file
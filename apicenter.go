@@ -0,0 +1,710 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+type ApiCenterFlags struct {
+	Subscription  string `name:"subscription" description:"The Azure subscription ID."`
+	ResourceGroup string `name:"resourcegroup" description:"The Azure resource group."`
+	ServiceName   string `name:"name" description:"The Azure API Center service name."`
+	Workspace     string `name:"workspace" description:"The API Center workspace, default if not set."`
+	Token         string `name:"token" description:"The Azure access token to call Azure with."`
+	ApiName       string `name:"api" description:"A specific API."`
+}
+
+// ApiCenterOnrampApi is an API with its versions, definitions and deployments, as onramped to API Center.
+type ApiCenterOnrampApi struct {
+	Name        string                   `json:"name"`
+	Properties  ApiCenterApiProperties   `json:"properties"`
+	Versions    []ApiCenterVersion       `json:"versions"`
+	Deployments []ApiCenterDeployment    `json:"deployments"`
+	Definitions []ApiCenterDefinitionRef `json:"definitions"`
+}
+
+type ApiCenterApiProperties struct {
+	Title                 string                           `json:"title"`
+	Kind                  string                           `json:"kind"`
+	Description           string                           `json:"description,omitempty"`
+	Summary               string                           `json:"summary,omitempty"`
+	Contacts              []ApiCenterContact               `json:"contacts,omitempty"`
+	ExternalDocumentation []ApiCenterExternalDocumentation `json:"externalDocumentation,omitempty"`
+}
+
+type ApiCenterContact struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type ApiCenterExternalDocumentation struct {
+	Title string `json:"title,omitempty"`
+	Url   string `json:"url"`
+}
+
+type ApiCenterVersion struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Title          string `json:"title"`
+		LifecycleStage string `json:"lifecycleStage"`
+	} `json:"properties"`
+}
+
+// ApiCenterDefinitionRef is a definition of a version, with the local file holding its specification.
+type ApiCenterDefinitionRef struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	SpecType    string `json:"specType"`
+	SpecVersion string `json:"specVersion,omitempty"`
+	SpecFile    string `json:"specFile"`
+}
+
+type ApiCenterDeployment struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Title         string `json:"title"`
+		Description   string `json:"description,omitempty"`
+		EnvironmentId string `json:"environmentId"`
+		DefinitionId  string `json:"definitionId,omitempty"`
+		State         string `json:"state"`
+		Server        struct {
+			RuntimeUri []string `json:"runtimeUri"`
+		} `json:"server"`
+	} `json:"properties"`
+}
+
+type ApiCenterEnvironment struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Title  string                      `json:"title"`
+		Kind   string                      `json:"kind"`
+		Server *ApiCenterEnvironmentServer `json:"server,omitempty"`
+	} `json:"properties"`
+}
+
+// ApiCenterExportApi is an API exported from API Center, with its versions, definitions and deployments.
+type ApiCenterExportApi struct {
+	Id          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Properties  ApiCenterApiProperties   `json:"properties"`
+	Versions    []ApiCenterExportVersion `json:"versions"`
+	Deployments []ApiCenterDeployment    `json:"deployments"`
+}
+
+type ApiCenterExportVersion struct {
+	ApiCenterVersion
+	Definitions []ApiCenterDefinition `json:"definitions"`
+}
+
+type ApiCenterDefinition struct {
+	Name       string `json:"name"`
+	Properties struct {
+		Title         string `json:"title"`
+		Description   string `json:"description,omitempty"`
+		Specification *struct {
+			Name    string `json:"name"`
+			Version string `json:"version,omitempty"`
+		} `json:"specification,omitempty"`
+	} `json:"properties"`
+	// SpecFile is the local file the exported specification is stored in
+	SpecFile string `json:"specFile,omitempty"`
+}
+
+type ApiCenterEnvironmentServer struct {
+	Type string `json:"type"`
+}
+
+// API Center server types of the platforms APIs are offramped from, AppSync has no server type
+var apiCenterServerTypes = map[string]string{
+	"azure-api-management": "Azure API Management",
+	"aws-api-gateway":      "AWS API Gateway",
+	"apigee":               "Apigee API Management",
+}
+
+func apiCenterOnramp(flags *ApiCenterFlags) error {
+	generalBaseDir := "src/main/general/apiproxies"
+	baseDir := "src/main/apicenter"
+
+	entries, err := os.ReadDir(generalBaseDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Onramping general APIs to API Center...")
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		var generalApi GeneralApi
+		byteValue, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + e.Name() + ".json")
+		if err != nil {
+			continue
+		}
+		json.Unmarshal(byteValue, &generalApi)
+		fmt.Println(e.Name())
+
+		var api ApiCenterOnrampApi
+		api.Name = e.Name()
+		api.Properties.Title = generalApi.DisplayName
+		api.Properties.Description = generalApi.Description
+		api.Properties.Kind = getApiCenterKind(generalApi.Protocol)
+		if generalApi.OwnerName != "" || generalApi.OwnerEmail != "" {
+			api.Properties.Contacts = []ApiCenterContact{{Name: generalApi.OwnerName, Email: generalApi.OwnerEmail}}
+		}
+		if generalApi.DocumentationUrl != "" {
+			api.Properties.ExternalDocumentation = []ApiCenterExternalDocumentation{{Title: "Documentation", Url: generalApi.DocumentationUrl}}
+		}
+
+		fileEntries, _ := os.ReadDir(generalBaseDir + "/" + e.Name())
+		for _, f := range fileEntries {
+			if !isGeneralDeploymentFile(f.Name()) || strings.HasSuffix(f.Name(), "-apicenter.json") {
+				// deployments offramped from API Center are already in API Center, their platform id
+				// is the one of their environment so only the file suffix identifies them
+				continue
+			}
+
+			var generalDeploymentApi GeneralApi
+			byteValue, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + f.Name())
+			if err == nil {
+				json.Unmarshal(byteValue, &generalDeploymentApi)
+			}
+			if generalDeploymentApi.Name == "" {
+				continue
+			}
+
+			versionName := getGeneralVersionName(f.Name())
+			if generalDeploymentApi.VersionName != "" {
+				versionName = generalDeploymentApi.VersionName
+			}
+			versionName = getAzureSlug(versionName)
+
+			versionFound := false
+			for _, version := range api.Versions {
+				versionFound = versionFound || version.Name == versionName
+			}
+			if !versionFound {
+				var version ApiCenterVersion
+				version.Name = versionName
+				version.Properties.Title = generalDeploymentApi.Version
+				if version.Properties.Title == "" {
+					version.Properties.Title = versionName
+				}
+				version.Properties.LifecycleStage = "production"
+				api.Versions = append(api.Versions, version)
+			}
+
+			// create a definition for each spec of the deployment
+			definitionId := ""
+			definitionNames := map[string]bool{}
+			for _, format := range generalSpecFormats {
+				spec, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + generalDeploymentApi.Name + format.Suffix)
+				if err != nil || definitionNames[getApiHubSpecId(generalDeploymentApi.Name, format)] {
+					continue
+				}
+				definitionNames[getApiHubSpecId(generalDeploymentApi.Name, format)] = true
+
+				definition := ApiCenterDefinitionRef{Name: getAzureSlug(getApiHubSpecId(generalDeploymentApi.Name, format)), Version: versionName, Title: generalDeploymentApi.DisplayName + " (" + format.DisplayName + ")", SpecType: format.SpecType, SpecFile: generalDeploymentApi.Name + format.Suffix}
+				if format.SpecType == "openapi" {
+					definition.SpecVersion = gjson.GetBytes(spec, "openapi").String()
+					if definition.SpecVersion == "" {
+						definition.SpecVersion = gjson.GetBytes(spec, "swagger").String()
+					}
+				} else if format.SpecType == "asyncapi" {
+					definition.SpecVersion = gjson.GetBytes(spec, "asyncapi").String()
+				}
+				api.Definitions = append(api.Definitions, definition)
+				if definitionId == "" {
+					definitionId = "/workspaces/" + getApiCenterWorkspace(flags) + "/apis/" + api.Name + "/versions/" + versionName + "/definitions/" + definition.Name
+				}
+
+				os.MkdirAll(baseDir+"/apiproxies/"+e.Name(), 0755)
+				os.WriteFile(baseDir+"/apiproxies/"+e.Name()+"/"+definition.SpecFile, spec, 0644)
+			}
+
+			// deployments are grouped in an environment per platform and region
+			environment := getApiCenterEnvironment(generalDeploymentApi)
+			bytes, _ := json.MarshalIndent(environment, "", "  ")
+			os.MkdirAll(baseDir+"/environments", 0755)
+			os.WriteFile(baseDir+"/environments/"+environment.Name+".json", bytes, 0644)
+
+			var deployment ApiCenterDeployment
+			deployment.Name = getAzureSlug(generalDeploymentApi.Name)
+			deployment.Properties.Title = generalDeploymentApi.DisplayName + " (" + generalDeploymentApi.PlatformName + ")"
+			deployment.Properties.Description = generalDeploymentApi.Description
+			deployment.Properties.EnvironmentId = "/workspaces/" + getApiCenterWorkspace(flags) + "/environments/" + environment.Name
+			deployment.Properties.DefinitionId = definitionId
+			deployment.Properties.State = "active"
+			deployment.Properties.Server.RuntimeUri = []string{}
+			if generalDeploymentApi.GatewayUrl != "" {
+				deployment.Properties.Server.RuntimeUri = append(deployment.Properties.Server.RuntimeUri, generalDeploymentApi.GatewayUrl)
+			}
+			api.Deployments = append(api.Deployments, deployment)
+		}
+
+		bytes, _ := json.MarshalIndent(api, "", "  ")
+		os.MkdirAll(baseDir+"/apiproxies/"+e.Name(), 0755)
+		os.WriteFile(baseDir+"/apiproxies/"+e.Name()+"/"+e.Name()+".json", bytes, 0644)
+	}
+
+	return nil
+}
+
+func apiCenterImport(flags *ApiCenterFlags) error {
+	baseDir := "src/main/apicenter"
+	if flags.Subscription == "" {
+		fmt.Println("No subscription given, cannot import APIs to API Center.")
+		return nil
+	} else if flags.ResourceGroup == "" {
+		fmt.Println("No resource group given, cannot import APIs to API Center.")
+		return nil
+	} else if flags.ServiceName == "" {
+		fmt.Println("No service name given, cannot import APIs to API Center.")
+		return nil
+	}
+
+	token := getAzureAccessToken(&AzureFlags{Token: flags.Token})
+	if token == "" {
+		fmt.Println("Could not get valid Azure token, cannot import APIs to API Center.")
+		return nil
+	}
+
+	workspaceUrl := getApiCenterWorkspaceUrl(flags)
+
+	environmentEntries, _ := os.ReadDir(baseDir + "/environments")
+	for _, e := range environmentEntries {
+		var environment ApiCenterEnvironment
+		byteValue, err := os.ReadFile(baseDir + "/environments/" + e.Name())
+		if err == nil {
+			json.Unmarshal(byteValue, &environment)
+			fmt.Println("Creating environment " + environment.Name + "...")
+			bytes, _ := json.Marshal(map[string]any{"properties": environment.Properties})
+			putAzureResource(workspaceUrl+"/environments/"+environment.Name+"?api-version=2024-03-01", bytes, token)
+		}
+	}
+
+	entries, err := os.ReadDir(baseDir + "/apiproxies")
+	if err != nil {
+		fmt.Println("No onramped APIs found, run apicenter apis onramp first.")
+		return nil
+	}
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		var api ApiCenterOnrampApi
+		byteValue, err := os.ReadFile(baseDir + "/apiproxies/" + e.Name() + "/" + e.Name() + ".json")
+		if err != nil {
+			continue
+		}
+		json.Unmarshal(byteValue, &api)
+
+		apiUrl := workspaceUrl + "/apis/" + api.Name
+		fmt.Println("Creating API " + api.Name + "...")
+		bytes, _ := json.Marshal(map[string]any{"properties": api.Properties})
+		if !putAzureResource(apiUrl+"?api-version=2024-03-01", bytes, token) {
+			continue
+		}
+
+		for _, version := range api.Versions {
+			fmt.Println("Creating API version " + version.Name + "...")
+			bytes, _ := json.Marshal(map[string]any{"properties": version.Properties})
+			putAzureResource(apiUrl+"/versions/"+version.Name+"?api-version=2024-03-01", bytes, token)
+		}
+
+		for _, definition := range api.Definitions {
+			fmt.Println("Creating API definition " + definition.Name + "...")
+			definitionUrl := apiUrl + "/versions/" + definition.Version + "/definitions/" + definition.Name
+			bytes, _ := json.Marshal(map[string]any{"properties": map[string]string{"title": definition.Title}})
+			if putAzureResource(definitionUrl+"?api-version=2024-03-01", bytes, token) {
+				spec, err := os.ReadFile(baseDir + "/apiproxies/" + e.Name() + "/" + definition.SpecFile)
+				if err == nil {
+					bytes, _ := json.Marshal(map[string]any{"format": "inline", "value": string(spec), "specification": map[string]string{"name": definition.SpecType, "version": definition.SpecVersion}})
+					sendAzureResource(http.MethodPost, definitionUrl+"/importSpecification?api-version=2024-03-01", bytes, token)
+				}
+			}
+		}
+
+		for _, deployment := range api.Deployments {
+			fmt.Println("Creating API deployment " + deployment.Name + "...")
+			bytes, _ := json.Marshal(map[string]any{"properties": deployment.Properties})
+			putAzureResource(apiUrl+"/deployments/"+deployment.Name+"?api-version=2024-03-01", bytes, token)
+		}
+	}
+
+	return nil
+}
+
+func apiCenterExportMin(flags *ApiCenterFlags) error {
+	_, err := apiCenterExport(flags)
+	return err
+}
+
+func apiCenterExport(flags *ApiCenterFlags) ([]string, error) {
+	baseDir := "src/main/apicenter/export"
+	results := []string{}
+	if flags.Subscription == "" {
+		fmt.Println("No subscription given, cannot export API Center APIs.")
+		return results, nil
+	} else if flags.ResourceGroup == "" {
+		fmt.Println("No resource group given, cannot export API Center APIs.")
+		return results, nil
+	} else if flags.ServiceName == "" {
+		fmt.Println("No service name given, cannot export API Center APIs.")
+		return results, nil
+	}
+
+	token := getAzureAccessToken(&AzureFlags{Token: flags.Token})
+	if token == "" {
+		fmt.Println("Could not get valid Azure token, cannot export API Center APIs.")
+		return results, nil
+	}
+
+	fmt.Println("Exporting API Center APIs from service " + flags.ServiceName + "...")
+	workspaceUrl := getApiCenterWorkspaceUrl(flags)
+
+	// environments tell which platform the deployments run on
+	var environments []ApiCenterEnvironment
+	json.Unmarshal(getApiCenterValues(workspaceUrl+"/environments?api-version=2024-03-01", token), &environments)
+	for _, environment := range environments {
+		bytes, _ := json.MarshalIndent(environment, "", "  ")
+		os.MkdirAll(baseDir+"/environments", 0755)
+		os.WriteFile(baseDir+"/environments/"+environment.Name+".json", bytes, 0644)
+	}
+
+	var apis []ApiCenterExportApi
+	json.Unmarshal(getApiCenterValues(workspaceUrl+"/apis?api-version=2024-03-01", token), &apis)
+	for _, api := range apis {
+		if flags.ApiName != "" && flags.ApiName != api.Name {
+			continue
+		}
+
+		fmt.Println("Exporting " + api.Name + "...")
+		apiUrl := workspaceUrl + "/apis/" + api.Name
+		os.MkdirAll(baseDir+"/apiproxies/"+api.Name, 0755)
+
+		json.Unmarshal(getApiCenterValues(apiUrl+"/versions?api-version=2024-03-01", token), &api.Versions)
+		for i, version := range api.Versions {
+			versionUrl := apiUrl + "/versions/" + version.Name
+			json.Unmarshal(getApiCenterValues(versionUrl+"/definitions?api-version=2024-03-01", token), &api.Versions[i].Definitions)
+			for j, definition := range api.Versions[i].Definitions {
+				format, found := getApiCenterSpecFormat(definition)
+				if !found {
+					continue
+				}
+
+				// definitions without an imported specification have nothing to export
+				spec := postApiCenterResource(versionUrl+"/definitions/"+definition.Name+"/exportSpecification?api-version=2024-03-01", token)
+				value := []byte(gjson.GetBytes(spec, "value").String())
+				if format.SpecType == "openapi" && len(value) > 0 {
+					var err error
+					if value, err = getGeneralJsonSpec(value); err != nil {
+						fmt.Println("  >> Could not convert the YAML spec of " + definition.Name + " to JSON, " + err.Error())
+						continue
+					}
+				}
+				if len(value) > 0 {
+					api.Versions[i].Definitions[j].SpecFile = version.Name + "-" + definition.Name + format.Suffix
+					os.WriteFile(baseDir+"/apiproxies/"+api.Name+"/"+api.Versions[i].Definitions[j].SpecFile, value, 0644)
+				}
+			}
+		}
+
+		json.Unmarshal(getApiCenterValues(apiUrl+"/deployments?api-version=2024-03-01", token), &api.Deployments)
+
+		bytes, _ := json.MarshalIndent(api, "", "  ")
+		os.WriteFile(baseDir+"/apiproxies/"+api.Name+"/"+api.Name+".json", bytes, 0644)
+		results = append(results, api.Name)
+	}
+
+	return results, nil
+}
+
+func apiCenterOfframp(flags *ApiCenterFlags) error {
+	apiCenterBaseDir := "src/main/apicenter/export"
+	baseDir := "src/main/general/apiproxies"
+
+	if flags.Subscription == "" {
+		fmt.Println("No subscription given, cannot offramp API Center APIs.")
+		return nil
+	} else if flags.ResourceGroup == "" {
+		fmt.Println("No resource group given, cannot offramp API Center APIs.")
+		return nil
+	} else if flags.ServiceName == "" {
+		fmt.Println("No service name given, cannot offramp API Center APIs.")
+		return nil
+	}
+
+	entries, err := os.ReadDir(apiCenterBaseDir + "/apiproxies")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Offramping API Center APIs to general...")
+
+	environments := map[string]ApiCenterEnvironment{}
+	environmentEntries, _ := os.ReadDir(apiCenterBaseDir + "/environments")
+	for _, e := range environmentEntries {
+		var environment ApiCenterEnvironment
+		byteValue, err := os.ReadFile(apiCenterBaseDir + "/environments/" + e.Name())
+		if err == nil {
+			json.Unmarshal(byteValue, &environment)
+			environments[environment.Name] = environment
+		}
+	}
+
+	portalUrl := "https://portal.azure.com/#resource/subscriptions/" + flags.Subscription + "/resourceGroups/" + flags.ResourceGroup + "/providers/Microsoft.ApiCenter/services/" + flags.ServiceName
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		var api ApiCenterExportApi
+		byteValue, err := os.ReadFile(apiCenterBaseDir + "/apiproxies/" + e.Name() + "/" + e.Name() + ".json")
+		if err != nil {
+			continue
+		}
+		json.Unmarshal(byteValue, &api)
+		fmt.Println(e.Name())
+
+		var generalApi GeneralApi
+		generalApi.DisplayName = api.Properties.Title
+		generalApi.Description = api.Properties.Description
+		if generalApi.Description == "" {
+			generalApi.Description = api.Properties.Summary
+		}
+		if len(api.Properties.Contacts) > 0 {
+			generalApi.OwnerName = api.Properties.Contacts[0].Name
+			generalApi.OwnerEmail = api.Properties.Contacts[0].Email
+		}
+		if len(api.Properties.ExternalDocumentation) > 0 {
+			generalApi.DocumentationUrl = api.Properties.ExternalDocumentation[0].Url
+		}
+		if kind := getApiCenterKind(api.Properties.Kind); kind != "rest" {
+			generalApi.Protocol = kind
+		}
+		generalApi.PlatformId = "azure-api-center"
+		generalApi.PlatformName = "Azure API Center"
+		generalApi.PlatformResourceUri = portalUrl + "/apis/" + api.Name
+
+		os.MkdirAll(baseDir+"/"+e.Name(), 0755)
+		writeGeneralApi(e.Name(), generalApi)
+
+		deployedVersions := map[string]bool{}
+		for _, deployment := range api.Deployments {
+			versionName, definition := getApiCenterDefinition(api, deployment.Properties.DefinitionId)
+			deployedVersions[versionName] = true
+
+			generalDeploymentApi := generalApi
+			generalDeploymentApi.Name = e.Name() + "-" + deployment.Name + "-apicenter"
+			generalDeploymentApi.VersionName = versionName
+			generalDeploymentApi.Version = getApiCenterVersionTitle(api, versionName)
+			if deployment.Properties.Description != "" {
+				generalDeploymentApi.Description = deployment.Properties.Description
+			}
+			if len(deployment.Properties.Server.RuntimeUri) > 0 {
+				generalDeploymentApi.GatewayUrl = deployment.Properties.Server.RuntimeUri[0]
+			}
+			generalDeploymentApi.PlatformResourceUri = portalUrl + "/apis/" + api.Name + "/deployments/" + deployment.Name
+
+			// deployments in environments of a known platform keep that platform
+			s := strings.Split(deployment.Properties.EnvironmentId, "/")
+			if environment, ok := environments[s[len(s)-1]]; ok {
+				generalDeploymentApi.Gateway = environment.Properties.Title
+				if environment.Properties.Server != nil {
+					if platformId := getApiCenterPlatformId(environment.Properties.Server.Type); platformId != "" {
+						generalDeploymentApi.PlatformId = platformId
+						generalDeploymentApi.PlatformName = environment.Properties.Server.Type
+					}
+				}
+			}
+
+			writeApiCenterGeneralDeployment(apiCenterBaseDir+"/apiproxies/"+e.Name(), baseDir+"/"+e.Name(), generalDeploymentApi, definition)
+		}
+
+		// versions that are not deployed anywhere still carry their specs
+		for _, version := range api.Versions {
+			if deployedVersions[version.Name] {
+				continue
+			}
+
+			generalDeploymentApi := generalApi
+			generalDeploymentApi.Name = e.Name() + "-" + version.Name + "-apicenter"
+			generalDeploymentApi.VersionName = version.Name
+			generalDeploymentApi.Version = version.Properties.Title
+			generalDeploymentApi.PlatformResourceUri = portalUrl + "/apis/" + api.Name + "/versions/" + version.Name
+
+			var definition ApiCenterDefinition
+			if len(version.Definitions) > 0 {
+				definition = version.Definitions[0]
+			}
+			writeApiCenterGeneralDeployment(apiCenterBaseDir+"/apiproxies/"+e.Name(), baseDir+"/"+e.Name(), generalDeploymentApi, definition)
+		}
+	}
+
+	return nil
+}
+
+func writeApiCenterGeneralDeployment(apiCenterDir string, generalDir string, generalDeploymentApi GeneralApi, definition ApiCenterDefinition) {
+	bytes, _ := json.MarshalIndent(generalDeploymentApi, "", "  ")
+	os.WriteFile(generalDir+"/"+generalDeploymentApi.Name+".json", bytes, 0644)
+
+	for _, format := range generalSpecFormats {
+		if definition.SpecFile != "" && strings.HasSuffix(definition.SpecFile, format.Suffix) {
+			spec, err := os.ReadFile(apiCenterDir + "/" + definition.SpecFile)
+			if err == nil {
+				os.WriteFile(generalDir+"/"+generalDeploymentApi.Name+format.Suffix, spec, 0644)
+			}
+		}
+	}
+}
+
+// getApiCenterDefinition returns the version name and definition a definition id like /workspaces/default/apis/x/versions/v1/definitions/d points to.
+func getApiCenterDefinition(api ApiCenterExportApi, definitionId string) (string, ApiCenterDefinition) {
+	var versionName, definitionName string
+	parts := strings.Split(definitionId, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "versions" {
+			versionName = parts[i+1]
+		} else if parts[i] == "definitions" {
+			definitionName = parts[i+1]
+		}
+	}
+
+	for _, version := range api.Versions {
+		if version.Name == versionName {
+			for _, definition := range version.Definitions {
+				if definition.Name == definitionName {
+					return versionName, definition
+				}
+			}
+		}
+	}
+
+	return versionName, ApiCenterDefinition{}
+}
+
+// getApiCenterPlatformId returns the general platform id of an API Center environment server type.
+func getApiCenterPlatformId(serverType string) string {
+	switch serverType {
+	case "Azure API Management":
+		return "azure-api-management"
+	case "AWS API Gateway":
+		return "aws-api-gateway"
+	case "Apigee API Management":
+		return "apigee"
+	}
+
+	return ""
+}
+
+func getApiCenterVersionTitle(api ApiCenterExportApi, versionName string) string {
+	for _, version := range api.Versions {
+		if version.Name == versionName {
+			return version.Properties.Title
+		}
+	}
+
+	return versionName
+}
+
+func getApiCenterSpecFormat(definition ApiCenterDefinition) (GeneralSpecFormat, bool) {
+	if definition.Properties.Specification != nil {
+		for _, format := range generalSpecFormats {
+			if format.SpecType == definition.Properties.Specification.Name {
+				return format, true
+			}
+		}
+	}
+
+	return GeneralSpecFormat{}, false
+}
+
+// getApiCenterValues returns the values of all pages of an API Center list as one JSON array.
+func getApiCenterValues(resourceUrl string, token string) []byte {
+	values := []json.RawMessage{}
+	for resourceUrl != "" {
+		body := getAzureResource(resourceUrl, token)
+		for _, value := range gjson.GetBytes(body, "value").Array() {
+			values = append(values, json.RawMessage(value.Raw))
+		}
+		resourceUrl = gjson.GetBytes(body, "nextLink").String()
+	}
+
+	bytes, _ := json.Marshal(values)
+	return bytes
+}
+
+func postApiCenterResource(resourceUrl string, token string) []byte {
+	var result []byte
+	req, _ := http.NewRequest(http.MethodPost, resourceUrl, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && resp.StatusCode == 200 {
+			result = body
+		}
+	}
+
+	return result
+}
+
+func apiCenterCleanLocal(flags *ApiCenterFlags) error {
+	var baseDir = "src/main/apicenter"
+	os.RemoveAll(baseDir)
+	return nil
+}
+
+func getApiCenterKind(protocol string) string {
+	switch protocol {
+	case "graphql", "soap", "websocket":
+		return protocol
+	}
+
+	return "rest"
+}
+
+// getApiCenterEnvironment returns the environment of a deployment, named after its platform and region or gateway.
+func getApiCenterEnvironment(generalApi GeneralApi) ApiCenterEnvironment {
+	var environment ApiCenterEnvironment
+	environment.Name = generalApi.PlatformId
+	environment.Properties.Title = generalApi.PlatformName
+	if location := strings.TrimSpace(generalApi.Gateway + " " + generalApi.Region); location != "" {
+		environment.Name = environment.Name + "-" + location
+		environment.Properties.Title = environment.Properties.Title + " (" + location + ")"
+	}
+	environment.Name = getAzureSlug(environment.Name)
+	environment.Properties.Kind = "production"
+	if serverType, ok := apiCenterServerTypes[generalApi.PlatformId]; ok {
+		environment.Properties.Server = &ApiCenterEnvironmentServer{Type: serverType}
+	}
+
+	return environment
+}
+
+func getApiCenterWorkspace(flags *ApiCenterFlags) string {
+	if flags.Workspace == "" {
+		return "default"
+	}
+
+	return flags.Workspace
+}
+
+func getApiCenterWorkspaceUrl(flags *ApiCenterFlags) string {
+	return "https://management.azure.com/subscriptions/" + flags.Subscription + "/resourceGroups/" + flags.ResourceGroup + "/providers/Microsoft.ApiCenter/services/" + flags.ServiceName + "/workspaces/" + getApiCenterWorkspace(flags)
+}
@@ -5,16 +5,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tidwall/gjson"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -29,6 +37,15 @@ type ApigeeApi struct {
 	ApiProxyType string   `json:"apiProxyType"`
 }
 
+type ApigeeSharedFlows struct {
+	SharedFlows []ApigeeApi `json:"sharedFlows"`
+}
+
+// ApigeeOnrampLedger records the proxies generated by onramps, so that only those are regenerated.
+type ApigeeOnrampLedger struct {
+	Proxies map[string]string `json:"proxies"`
+}
+
 type ApigeeEnvironment struct {
 	Proxies     []ApigeeEnvironmentProxy `json:"proxies"`
 	SharedFlows []ApigeeEnvironmentProxy `json:"sharedflows"`
@@ -43,18 +60,41 @@ type ApigeeDevelopers struct {
 }
 
 type ApigeeDeveloper struct {
-	Email     string `json:"email"`
-	UserName  string `json:"userName"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
+	Email      string            `json:"email"`
+	UserName   string            `json:"userName"`
+	FirstName  string            `json:"firstName"`
+	LastName   string            `json:"lastName"`
+	Attributes []ApigeeAttribute `json:"attributes,omitempty"`
+}
+
+type ApigeeDeveloperApps struct {
+	Apps []ApigeeDeveloperApp `json:"app"`
 }
 
 type ApigeeDeveloperApp struct {
-	DeveloperEmail string   `json:"developerEmail"`
-	Name           string   `json:"name"`
-	DisplayName    string   `json:"displayName"`
-	ApiProducts    []string `json:"apiProducts"`
-	ExpiryType     string   `json:"expiryType"`
+	DeveloperEmail string                `json:"developerEmail"`
+	Name           string                `json:"name"`
+	DisplayName    string                `json:"displayName"`
+	ApiProducts    []string              `json:"apiProducts"`
+	ExpiryType     string                `json:"expiryType,omitempty"`
+	KeyExpiresIn   string                `json:"keyExpiresIn,omitempty"`
+	CallbackUrl    string                `json:"callbackUrl,omitempty"`
+	Scopes         []string              `json:"scopes,omitempty"`
+	Attributes     []ApigeeAttribute     `json:"attributes,omitempty"`
+	Credentials    []ApigeeAppCredential `json:"credentials,omitempty"`
+}
+
+// ApigeeAppCredential is an app key, it is only read from Apigee and never stored locally.
+type ApigeeAppCredential struct {
+	ConsumerKey    string                    `json:"consumerKey"`
+	ConsumerSecret string                    `json:"consumerSecret"`
+	Status         string                    `json:"status"`
+	ApiProducts    []ApigeeCredentialProduct `json:"apiProducts"`
+}
+
+type ApigeeCredentialProduct struct {
+	ApiProduct string `json:"apiproduct"`
+	Status     string `json:"status"`
 }
 
 type ApigeeProducts struct {
@@ -62,12 +102,94 @@ type ApigeeProducts struct {
 }
 
 type ApigeeProduct struct {
+	Name          string            `json:"name"`
+	DisplayName   string            `json:"displayName"`
+	Description   string            `json:"description,omitempty"`
+	ApprovalType  string            `json:"approvalType,omitempty"`
+	Scopes        []string          `json:"scopes"`
+	Environments  []string          `json:"environments"`
+	ApiResources  []string          `json:"apiResources"`
+	Proxies       []string          `json:"proxies"`
+	Quota         string            `json:"quota,omitempty"`
+	QuotaInterval string            `json:"quotaInterval,omitempty"`
+	QuotaTimeUnit string            `json:"quotaTimeUnit,omitempty"`
+	Attributes    []ApigeeAttribute `json:"attributes,omitempty"`
+	// operation groups are kept as they are, so products that use them round-trip
+	OperationGroup json.RawMessage `json:"operationGroup,omitempty"`
+}
+
+type ApigeeAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ApigeeDeployments struct {
+	Deployments []ApigeeDeployment `json:"deployments"`
+}
+
+type ApigeeDeployment struct {
+	Environment     string `json:"environment"`
+	ApiProxy        string `json:"apiProxy"`
+	Revision        string `json:"revision"`
+	DeployStartTime string `json:"deployStartTime"`
+}
+
+type ApigeeEnvironmentGroups struct {
+	EnvironmentGroups []ApigeeEnvironmentGroup `json:"environmentGroups"`
+}
+
+// ApigeeEnvironmentGroup is an environment group with the environments attached to it.
+type ApigeeEnvironmentGroup struct {
 	Name         string   `json:"name"`
-	DisplayName  string   `json:"displayName"`
-	Scopes       []string `json:"scopes"`
-	Environments []string `json:"environments"`
-	ApiResources []string `json:"apiResources"`
-	Proxies      []string `json:"proxies"`
+	Hostnames    []string `json:"hostnames"`
+	Environments []string `json:"environments,omitempty"`
+}
+
+type ApigeeEnvironmentGroupAttachments struct {
+	EnvironmentGroupAttachments []struct {
+		Environment string `json:"environment"`
+	} `json:"environmentGroupAttachments"`
+}
+
+// ApigeeBundleProxy is the apiproxy/<name>.xml descriptor of a proxy bundle.
+type ApigeeBundleProxy struct {
+	Name            string   `xml:"name,attr"`
+	Revision        string   `xml:"revision,attr"`
+	DisplayName     string   `xml:"DisplayName"`
+	Description     string   `xml:"Description"`
+	BasePaths       string   `xml:"BasePaths"`
+	CreatedAt       int64    `xml:"CreatedAt"`
+	LastModifiedAt  int64    `xml:"LastModifiedAt"`
+	Policies        []string `xml:"Policies>Policy"`
+	ProxyEndpoints  []string `xml:"ProxyEndpoints>ProxyEndpoint"`
+	TargetEndpoints []string `xml:"TargetEndpoints>TargetEndpoint"`
+}
+
+type ApigeeBundleProxyEndpoint struct {
+	Name         string   `xml:"name,attr"`
+	BasePath     string   `xml:"HTTPProxyConnection>BasePath"`
+	VirtualHosts []string `xml:"HTTPProxyConnection>VirtualHost"`
+}
+
+type ApigeeBundleTargetEndpoint struct {
+	Name    string `xml:"name,attr"`
+	Url     string `xml:"HTTPTargetConnection>URL"`
+	Path    string `xml:"HTTPTargetConnection>Path"`
+	Servers []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"HTTPTargetConnection>LoadBalancer>Server"`
+}
+
+// ApigeeBundlePolicy holds the fields of the verification policies that map to general security schemes.
+type ApigeeBundlePolicy struct {
+	XMLName   xml.Name
+	Name      string `xml:"name,attr"`
+	Operation string `xml:"Operation"`
+	APIKey    struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"APIKey"`
+	Issuer    string `xml:"Issuer"`
+	Audiences string `xml:"Audience"`
 }
 
 type ApigeeFlags struct {
@@ -79,6 +201,28 @@ type ApigeeFlags struct {
 	ApiProduct     string `name:"product" description:"A specific Apigee product."`
 	DeveloperEmail string `name:"developerEmail" description:"A specific Apigee developer email."`
 	ServiceAccount string `name:"serviceAccount" description:"A service account email to use for Apigee deployments."`
+	Security       string `name:"security" description:"Verify an apikey or oauth access token in onramped proxies."`
+}
+
+// getApigeeToken returns the given token, or else an access token of the default Google credentials.
+func getApigeeToken(flags *ApigeeFlags) string {
+	if flags.Token != "" {
+		return flags.Token
+	}
+
+	scopes := []string{
+		"https://www.googleapis.com/auth/cloud-platform",
+	}
+	credentials, err := google.FindDefaultCredentials(context.Background(), scopes...)
+	if err != nil {
+		return ""
+	}
+	token, err := credentials.TokenSource.Token()
+	if err != nil {
+		return ""
+	}
+
+	return token.AccessToken
 }
 
 func apigeeStatus(flags *ApigeeFlags) PlatformStatus {
@@ -220,6 +364,32 @@ func apigeeExport(flags *ApigeeFlags) error {
 			bytes, _ := json.MarshalIndent(environment, "", "  ")
 			os.WriteFile("src/main/apigee/environments/"+flags.Environment+"/deployments.json", bytes, 0644)
 		}
+
+		// the deployed revisions and environment group hostnames give the offramped gateway urls
+		deployments := getApigeeDeployments(flags.Project, flags.Token)
+		bytes, _ := json.MarshalIndent(deployments, "", "  ")
+		os.WriteFile("src/main/apigee/deployments.json", bytes, 0644)
+
+		// environments can run different revisions, so the bundle of each deployed revision is exported too
+		for _, deployment := range deployments.Deployments {
+			if flags.ApiName != "" && flags.ApiName != deployment.ApiProxy {
+				continue
+			}
+
+			revisionDir := "src/main/apigee/revisions/" + deployment.ApiProxy
+			bundle := getApigeeApiBundle(flags.Project, deployment.ApiProxy, deployment.Revision, flags.Token)
+			if bundle != nil {
+				os.RemoveAll(revisionDir + "/" + deployment.Revision)
+				os.MkdirAll(revisionDir, 0755)
+				os.WriteFile(revisionDir+"/"+deployment.Revision+".zip", bundle, 0644)
+				unzipApigeeBundle(revisionDir, deployment.Revision)
+				os.Remove(revisionDir + "/" + deployment.Revision + ".zip")
+			}
+		}
+
+		environmentGroups := getApigeeEnvironmentGroups(flags.Project, flags.Token)
+		bytes, _ = json.MarshalIndent(environmentGroups, "", "  ")
+		os.WriteFile("src/main/apigee/envgroups.json", bytes, 0644)
 	}
 
 	return nil
@@ -302,6 +472,19 @@ func apigeeDeploy(flags *ApigeeFlags) error {
 
 	apis, err := os.ReadDir(baseDir)
 	if err == nil {
+		// local shared flows the proxies call are deployed first
+		sharedFlows := []string{}
+		for _, e := range apis {
+			if flags.ApiName == "" || flags.ApiName == e.Name() {
+				for _, sharedFlow := range getApigeeBundleFlowCallouts(baseDir + "/" + e.Name() + "/apiproxy") {
+					if _, err := os.Stat("src/main/apigee/sharedflows/" + sharedFlow + "/sharedflowbundle"); err == nil && !slices.Contains(sharedFlows, sharedFlow) {
+						sharedFlows = append(sharedFlows, sharedFlow)
+					}
+				}
+			}
+		}
+		deployApigeeSharedFlows(flags, getApigeeSharedFlowOrder("src/main/apigee/sharedflows", sharedFlows))
+
 		for _, e := range apis {
 			if flags.ApiName == "" || flags.ApiName == e.Name() {
 				latestVersion := getApigeeApiLatestVersion(flags.Project, flags.Token, e.Name())
@@ -430,216 +613,1333 @@ func apigeeProductsClean(flags *ApigeeFlags) error {
 	return nil
 }
 
-func getApigeeApis(org string, token string) ApigeeProxies {
-	var apis ApigeeProxies
-	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis?includeRevisions=true", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+func apigeeProductsOnramp(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/products"
 
-	resp, err := http.DefaultClient.Do(req)
-	if err == nil {
-		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			json.Unmarshal(body, &apis)
-			//fmt.Println(string(body))
-		}
+	products := getGeneralProducts()
+	if len(products) == 0 {
+		fmt.Println("No general products found, nothing to onramp.")
+		return nil
 	}
 
-	return apis
-}
-
-func getApigeeApiProducts(org string, token string) ApigeeProducts {
-	var result ApigeeProducts
-	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apiproducts", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
-
-	resp, err := http.DefaultClient.Do(req)
+	var ledger ApigeeOnrampLedger
+	byteValue, err := os.ReadFile("src/main/apigee/onramp/ledger.json")
 	if err == nil {
-		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			json.Unmarshal(body, &result)
-			//fmt.Println(string(body))
-		}
+		json.Unmarshal(byteValue, &ledger)
 	}
 
-	return result
-}
+	fmt.Println("Onramping general products to Apigee products...")
+	os.MkdirAll(baseDir, 0755)
 
-func getApigeeDevelopers(org string, token string) ApigeeDevelopers {
-	var result ApigeeDevelopers
-	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/developers", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+	for _, generalProduct := range products {
+		if flags.ApiProduct == "" || flags.ApiProduct == generalProduct.Name {
+			fmt.Println(generalProduct.Name)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err == nil {
-		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			json.Unmarshal(body, &result)
-			//fmt.Println(string(body))
+			product := ApigeeProduct{Name: generalProduct.Name, DisplayName: generalProduct.DisplayName, Description: generalProduct.Description, ApprovalType: "auto", Scopes: []string{}, Environments: []string{}, ApiResources: []string{"/"}, Proxies: getApigeeProductProxies(generalProduct.Apis, ledger)}
+			if generalProduct.ApprovalRequired {
+				product.ApprovalType = "manual"
+			}
+			if flags.Environment != "" {
+				product.Environments = append(product.Environments, flags.Environment)
+			}
+			if generalProduct.QuotaLimit > 0 {
+				product.Quota = strconv.Itoa(generalProduct.QuotaLimit)
+				product.QuotaInterval = strconv.Itoa(generalProduct.QuotaInterval)
+				product.QuotaTimeUnit = generalProduct.QuotaTimeUnit
+			}
+
+			// Apigee products have no throttling settings, keep them as attributes for spike arrest policies
+			if generalProduct.RateLimit > 0 {
+				product.Attributes = append(product.Attributes, ApigeeAttribute{Name: "rateLimit", Value: strconv.FormatFloat(generalProduct.RateLimit, 'f', -1, 64)})
+			}
+			if generalProduct.BurstLimit > 0 {
+				product.Attributes = append(product.Attributes, ApigeeAttribute{Name: "burstLimit", Value: strconv.Itoa(generalProduct.BurstLimit)})
+			}
+			product.Attributes = append(product.Attributes, ApigeeAttribute{Name: "platform", Value: generalProduct.PlatformName})
+
+			bytes, _ := json.MarshalIndent(product, "", "  ")
+			os.WriteFile(baseDir+"/"+product.Name+".json", bytes, 0644)
 		}
 	}
 
-	return result
+	return nil
 }
 
-func getApigeeApiBundle(org string, api string, revision string, token string) []byte {
-	var bundle []byte
-
-	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis/"+api+"/revisions/"+revision+"?format=bundle", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+func apigeeOfframp(flags *ApigeeFlags) error {
+	apigeeBaseDir := "src/main/apigee/apiproxies"
+	baseDir := "src/main/general/apiproxies"
 
-	resp, err := http.DefaultClient.Do(req)
-	if err == nil {
-		bundle, _ = io.ReadAll(resp.Body)
+	entries, err := os.ReadDir(apigeeBaseDir)
+	if err != nil {
+		fmt.Println("No exported Apigee APIs found, run apigee apis export first.")
+		return nil
 	}
 
-	return bundle
-}
+	fmt.Println("Offramping Apigee APIs to general...")
 
-func unzipApigeeBundle(basePath string, name string) {
-	zipBaseBath := basePath + "/" + name
-	os.Mkdir(basePath+"/"+name, 0755)
-	archive, err := zip.OpenReader(basePath + "/" + name + ".zip")
-	if err != nil {
-		panic(err)
+	var deployments ApigeeDeployments
+	byteValue, err := os.ReadFile("src/main/apigee/deployments.json")
+	if err == nil {
+		json.Unmarshal(byteValue, &deployments)
 	}
-	defer archive.Close()
 
-	for _, f := range archive.File {
-		filePath := filepath.Join(zipBaseBath, f.Name)
+	var environmentGroups ApigeeEnvironmentGroups
+	byteValue, err = os.ReadFile("src/main/apigee/envgroups.json")
+	if err == nil {
+		json.Unmarshal(byteValue, &environmentGroups)
+	}
 
-		if !strings.HasPrefix(filePath, filepath.Clean(zipBaseBath)+string(os.PathSeparator)) {
-			fmt.Println("invalid file path")
-			return
-		}
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(filePath, os.ModePerm)
+	for _, e := range entries {
+		if !e.IsDir() || (flags.ApiName != "" && flags.ApiName != e.Name()) {
 			continue
 		}
 
-		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			panic(err)
-		}
-
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		bundleDir := apigeeBaseDir + "/" + e.Name() + "/apiproxy"
+		generalApi, err := getApigeeBundleApi(bundleDir, e.Name(), flags.Project)
 		if err != nil {
-			panic(err)
+			fmt.Println("  >> Skipping " + e.Name() + ", " + err.Error() + ".")
+			continue
 		}
+		fmt.Println(e.Name())
 
-		fileInArchive, err := f.Open()
-		if err != nil {
-			panic(err)
-		}
+		os.MkdirAll(baseDir+"/"+e.Name(), 0755)
+		writeGeneralApi(e.Name(), generalApi)
 
-		if _, err := io.Copy(dstFile, fileInArchive); err != nil {
-			panic(err)
+		// each environment the proxy is deployed to is a deployment, described by the bundle of the revision deployed there
+		found := false
+		for _, apigeeDeployment := range deployments.Deployments {
+			if apigeeDeployment.ApiProxy != e.Name() {
+				continue
+			}
+			found = true
+
+			deploymentBundleDir := bundleDir
+			deploymentApi := generalApi
+			revisionBundleDir := "src/main/apigee/revisions/" + e.Name() + "/" + apigeeDeployment.Revision + "/apiproxy"
+			if revisionApi, err := getApigeeBundleApi(revisionBundleDir, e.Name(), flags.Project); err == nil {
+				deploymentBundleDir = revisionBundleDir
+				deploymentApi = revisionApi
+			} else {
+				fmt.Println("  >> Revision " + apigeeDeployment.Revision + " deployed to " + apigeeDeployment.Environment + " was not exported, using the exported bundle.")
+			}
+
+			deployment := getApigeeApiDeployment(deploymentApi, e.Name(), apigeeDeployment, environmentGroups)
+			writeApigeeGeneralDeployment(baseDir+"/"+e.Name(), deployment, deploymentBundleDir)
 		}
 
-		dstFile.Close()
-		fileInArchive.Close()
+		if !found {
+			// undeployed proxies are offramped from the exported bundle
+			writeApigeeGeneralDeployment(baseDir+"/"+e.Name(), generalApi, bundleDir)
+		}
 	}
+
+	return nil
 }
 
-func zipApigeeBundle(name string) {
-	file, err := os.Create(name + ".zip")
-	if err != nil {
-		panic(err)
+func apigeeOnramp(flags *ApigeeFlags) error {
+	generalBaseDir := "src/main/general/apiproxies"
+	baseDir := "src/main/apigee/apiproxies"
+
+	if flags.Security != "" && flags.Security != "apikey" && flags.Security != "oauth" {
+		fmt.Println("Unknown security " + flags.Security + ", use apikey or oauth.")
+		return nil
 	}
-	defer file.Close()
 
-	w := zip.NewWriter(file)
-	defer w.Close()
+	entries, err := os.ReadDir(generalBaseDir)
+	if err != nil {
+		fmt.Println("No general APIs found, nothing to onramp.")
+		return nil
+	}
 
-	walker := func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		file, err := os.Open(path)
-		if err != nil {
-			return err
+	var environment ApigeeEnvironment
+	if flags.Environment != "" {
+		environment = ApigeeEnvironment{Proxies: []ApigeeEnvironmentProxy{}, SharedFlows: []ApigeeEnvironmentProxy{}}
+		byteValue, err := os.ReadFile("src/main/apigee/environments/" + flags.Environment + "/deployments.json")
+		if err == nil {
+			json.Unmarshal(byteValue, &environment)
 		}
-		defer file.Close()
+	}
 
-		// Ensure that `path` is not absolute; it should not start with "/".
-		// This snippet happens to work because I don't use
-		// absolute paths, but ensure your real-world code
-		// transforms path into a zip-root relative path.
-		f, err := w.Create(path)
-		if err != nil {
-			return err
+	// proxy folders not in the ledger were exported or written by hand, and are not overwritten
+	ledgerFile := "src/main/apigee/onramp/ledger.json"
+	ledger := ApigeeOnrampLedger{Proxies: map[string]string{}}
+	byteValue, err := os.ReadFile(ledgerFile)
+	if err == nil {
+		json.Unmarshal(byteValue, &ledger)
+		if ledger.Proxies == nil {
+			ledger.Proxies = map[string]string{}
 		}
+	}
 
-		_, err = io.Copy(f, file)
-		if err != nil {
-			return err
+	fmt.Println("Onramping general APIs to Apigee proxies...")
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
 		}
 
-		return nil
-	}
+		// one proxy is generated per version, from the first deployment
+		onrampedVersions := make(map[string]bool)
+		fileEntries, _ := os.ReadDir(generalBaseDir + "/" + e.Name())
+		for _, f := range fileEntries {
+			if !isGeneralDeploymentFile(f.Name()) {
+				continue
+			}
 
-	err = filepath.Walk("apiproxy", walker)
-	if err != nil {
-		panic(err)
-	}
-}
+			var generalDeploymentApi GeneralApi
+			byteValue, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + f.Name())
+			if err == nil {
+				json.Unmarshal(byteValue, &generalDeploymentApi)
+			}
+			if generalDeploymentApi.Name == "" || generalDeploymentApi.PlatformId == "apigee" {
+				// APIs offramped from Apigee are already in Apigee
+				continue
+			}
 
-func deleteApigeeApi(org string, token string, api string) {
-	req, _ := http.NewRequest(http.MethodDelete, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis/"+api, nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+			versionName := getGeneralVersionName(f.Name())
+			if generalDeploymentApi.VersionName != "" {
+				versionName = generalDeploymentApi.VersionName
+			}
+			if onrampedVersions[versionName] {
+				continue
+			}
+			onrampedVersions[versionName] = true
 
-	_, err := http.DefaultClient.Do(req)
-	if err != nil {
-		fmt.Println("Error deleting Apigee API: " + err.Error())
-	}
-}
+			proxyName := getAzureSlug(versionName)
+			fmt.Println(proxyName)
 
-func createApigeeApi(org string, token string, name string) error {
+			// the gateway path keeps version segments, so versions don't share a base path
+			basePath := generalDeploymentApi.BasePath
+			if gatewayUrl, err := url.Parse(generalDeploymentApi.GatewayUrl); err == nil && gatewayUrl.Path != "" && gatewayUrl.Path != "/" {
+				basePath = gatewayUrl.Path
+			}
+			if basePath == "" {
+				basePath = proxyName
+			}
+			basePath = "/" + strings.Trim(basePath, "/")
+
+			// pass through to the first http backend, or else to the current gateway
+			targetUrl := generalDeploymentApi.GatewayUrl
+			for _, backend := range generalDeploymentApi.Backends {
+				if backend.Type == "http" && strings.HasPrefix(backend.Url, "http") {
+					targetUrl = backend.Url
+					break
+				}
+			}
+			if !strings.HasPrefix(targetUrl, "http") {
+				fmt.Println("  >> Skipping " + generalDeploymentApi.Name + ", no backend or gateway url to proxy to.")
+				continue
+			}
 
-	fileDir, _ := os.Getwd()
-	fileName := name + ".zip"
-	filePath := path.Join(fileDir, fileName)
+			if _, err := os.Stat(baseDir + "/" + proxyName); err == nil && ledger.Proxies[proxyName] == "" {
+				fmt.Println("  >> Skipping " + proxyName + ", " + baseDir + "/" + proxyName + " was not generated by an onramp.")
+				continue
+			}
+			ledger.Proxies[proxyName] = e.Name()
+
+			bundleDir := baseDir + "/" + proxyName + "/apiproxy"
+			os.RemoveAll(baseDir + "/" + proxyName)
+			os.MkdirAll(bundleDir+"/proxies", 0755)
+			os.MkdirAll(bundleDir+"/targets", 0755)
+
+			policies := []string{}
+			preFlow := ""
+			switch flags.Security {
+			case "apikey":
+				os.MkdirAll(bundleDir+"/policies", 0755)
+				os.WriteFile(bundleDir+"/policies/VA-VerifyKey.xml", []byte(getApigeePolicyXml("VerifyAPIKey", "VA-VerifyKey", "\n  <APIKey ref=\"request.header.x-api-key\"/>")), 0644)
+				os.WriteFile(bundleDir+"/policies/AM-RemoveKey.xml", []byte(getApigeePolicyXml("AssignMessage", "AM-RemoveKey", "\n  <Remove>\n    <Headers>\n      <Header name=\"x-api-key\"/>\n    </Headers>\n  </Remove>\n  <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>")), 0644)
+				policies = append(policies, "VA-VerifyKey", "AM-RemoveKey")
+			case "oauth":
+				os.MkdirAll(bundleDir+"/policies", 0755)
+				os.WriteFile(bundleDir+"/policies/OA-VerifyToken.xml", []byte(getApigeePolicyXml("OAuthV2", "OA-VerifyToken", "\n  <Operation>VerifyAccessToken</Operation>")), 0644)
+				os.WriteFile(bundleDir+"/policies/AM-RemoveToken.xml", []byte(getApigeePolicyXml("AssignMessage", "AM-RemoveToken", "\n  <Remove>\n    <Headers>\n      <Header name=\"Authorization\"/>\n    </Headers>\n  </Remove>\n  <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>")), 0644)
+				policies = append(policies, "OA-VerifyToken", "AM-RemoveToken")
+			}
+			for _, policy := range policies {
+				preFlow = preFlow + "\n      <Step>\n        <Name>" + policy + "</Name>\n      </Step>"
+			}
 
-	file, _ := os.Open(filePath)
-	defer file.Close()
+			// a conditional flow per operation of the spec
+			flows := ""
+			resources := ""
+			spec, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + generalDeploymentApi.Name + "-oas.json")
+			if err == nil {
+				flows = getApigeeSpecFlows(spec)
+				os.MkdirAll(bundleDir+"/resources/oas", 0755)
+				os.WriteFile(bundleDir+"/resources/oas/"+proxyName+".json", spec, 0644)
+				resources = "\n  <Resources>\n    <Resource>oas://" + proxyName + ".json</Resource>\n  </Resources>"
+			}
 
-	//fmt.Println(filepath.Base(file.Name()))
+			policyList := ""
+			for _, policy := range policies {
+				policyList = policyList + "\n    <Policy>" + policy + "</Policy>"
+			}
+			descriptor := "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\n<APIProxy revision=\"1\" name=\"" + proxyName + "\">\n  <DisplayName>" + escapeXml(generalDeploymentApi.DisplayName) + "</DisplayName>\n  <Description>" + escapeXml(generalDeploymentApi.Description) + "</Description>\n  <BasePaths>" + escapeXml(basePath) + "</BasePaths>\n  <Policies>" + policyList + "\n  </Policies>\n  <ProxyEndpoints>\n    <ProxyEndpoint>default</ProxyEndpoint>\n  </ProxyEndpoints>" + resources + "\n  <TargetEndpoints>\n    <TargetEndpoint>default</TargetEndpoint>\n  </TargetEndpoints>\n</APIProxy>\n"
+			os.WriteFile(bundleDir+"/"+proxyName+".xml", []byte(descriptor), 0644)
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", filepath.Base(file.Name()))
-	io.Copy(part, file)
-	writer.Close()
+			proxyEndpoint := "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\n<ProxyEndpoint name=\"default\">\n  <PreFlow name=\"PreFlow\">\n    <Request>" + preFlow + "\n    </Request>\n    <Response/>\n  </PreFlow>\n  <Flows>" + flows + "\n  </Flows>\n  <PostFlow name=\"PostFlow\">\n    <Request/>\n    <Response/>\n  </PostFlow>\n  <HTTPProxyConnection>\n    <BasePath>" + escapeXml(basePath) + "</BasePath>\n  </HTTPProxyConnection>\n  <RouteRule name=\"default\">\n    <TargetEndpoint>default</TargetEndpoint>\n  </RouteRule>\n</ProxyEndpoint>\n"
+			os.WriteFile(bundleDir+"/proxies/default.xml", []byte(proxyEndpoint), 0644)
 
-	r, _ := http.NewRequest(http.MethodPost, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis?name="+name+"&action=import", body)
-	r.Header.Add("Content-Type", writer.FormDataContentType())
-	r.Header.Add("Authorization", "Bearer "+token)
-	client := &http.Client{}
-	resp, err := client.Do(r)
+			targetEndpoint := "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\n<TargetEndpoint name=\"default\">\n  <PreFlow name=\"PreFlow\">\n    <Request/>\n    <Response/>\n  </PreFlow>\n  <HTTPTargetConnection>\n    <URL>" + escapeXml(targetUrl) + "</URL>\n  </HTTPTargetConnection>\n</TargetEndpoint>\n"
+			os.WriteFile(bundleDir+"/targets/default.xml", []byte(targetEndpoint), 0644)
 
-	if resp.StatusCode != 200 {
-		fmt.Println("Error creating Apigee API: " + resp.Status)
+			if flags.Environment != "" && !slices.ContainsFunc(environment.Proxies, func(proxy ApigeeEnvironmentProxy) bool { return proxy.Name == proxyName }) {
+				environment.Proxies = append(environment.Proxies, ApigeeEnvironmentProxy{Name: proxyName})
+			}
+		}
 	}
 
-	return err
-}
+	bytes, _ := json.MarshalIndent(ledger, "", "  ")
+	os.MkdirAll("src/main/apigee/onramp", 0755)
+	os.WriteFile(ledgerFile, bytes, 0644)
 
-func deployApigeeApi(org string, token string, env string, name string, version string, serviceAccount string) error {
+	if flags.Environment != "" {
+		bytes, _ := json.MarshalIndent(environment, "", "  ")
+		os.MkdirAll("src/main/apigee/environments/"+flags.Environment, 0755)
+		os.WriteFile("src/main/apigee/environments/"+flags.Environment+"/deployments.json", bytes, 0644)
+	}
 
-	url := "https://apigee.googleapis.com/v1/organizations/" + org + "/environments/" + env + "/apis/" + name + "/revisions/" + version + "/deployments?override=true"
-	if serviceAccount != "" {
-		url = url + "&serviceAccount=" + serviceAccount
+	return nil
+}
+
+// getApigeeProductProxies returns the Apigee proxies of general APIs, the proxies onramped from them or the proxies they were offramped from.
+func getApigeeProductProxies(apis []string, ledger ApigeeOnrampLedger) []string {
+	proxyNames := []string{}
+	for proxyName := range ledger.Proxies {
+		proxyNames = append(proxyNames, proxyName)
 	}
+	slices.Sort(proxyNames)
+
+	proxies := []string{}
+	for _, api := range apis {
+		found := false
+		for _, proxyName := range proxyNames {
+			if ledger.Proxies[proxyName] == api {
+				proxies = append(proxies, proxyName)
+				found = true
+			}
+		}
 
-	r, _ := http.NewRequest(http.MethodPost, url, nil)
+		if apigeeDeployments, _ := filepath.Glob("src/main/general/apiproxies/" + api + "/*-apigee.json"); len(apigeeDeployments) > 0 && !slices.Contains(proxies, api) {
+			proxies = append(proxies, api)
+			found = true
+		}
+
+		if !found {
+			fmt.Println("  >> " + api + " has no Apigee proxy, run apigee apis onramp first.")
+		}
+	}
+
+	return proxies
+}
+
+// getApigeeSpecFlows returns a conditional flow for each operation of an OpenAPI spec.
+func getApigeeSpecFlows(spec []byte) string {
+	flows := ""
+	var re = regexp.MustCompile(`\{[^}]*\}`)
+	gjson.GetBytes(spec, "paths").ForEach(func(path, pathItem gjson.Result) bool {
+		pathItem.ForEach(func(method, operation gjson.Result) bool {
+			verb := strings.ToUpper(method.String())
+			if !slices.Contains([]string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH"}, verb) {
+				return true
+			}
+
+			name := operation.Get("operationId").String()
+			if name == "" {
+				name = verb + " " + path.String()
+			}
+			flows = flows + "\n    <Flow name=\"" + escapeXml(name) + "\">\n      <Description>" + escapeXml(operation.Get("summary").String()) + "</Description>\n      <Request/>\n      <Response/>\n      <Condition>(proxy.pathsuffix MatchesPath \"" + escapeXml(re.ReplaceAllString(path.String(), "*")) + "\") and (request.verb = \"" + verb + "\")</Condition>\n    </Flow>"
+			return true
+		})
+		return true
+	})
+
+	return flows
+}
+
+// getApigeeBundleApi returns the general API described by an exported proxy bundle.
+func getApigeeBundleApi(bundleDir string, name string, project string) (GeneralApi, error) {
+	var generalApi GeneralApi
+	var proxy ApigeeBundleProxy
+	descriptors, _ := filepath.Glob(bundleDir + "/*.xml")
+	if len(descriptors) == 0 {
+		return generalApi, errors.New("no bundle descriptor found in " + bundleDir)
+	}
+	byteValue, err := os.ReadFile(descriptors[0])
+	if err != nil || xml.Unmarshal(byteValue, &proxy) != nil {
+		return generalApi, errors.New("could not parse bundle descriptor " + descriptors[0])
+	}
+
+	generalApi.Name = name + "-apigee"
+	generalApi.DisplayName = proxy.DisplayName
+	if generalApi.DisplayName == "" {
+		generalApi.DisplayName = name
+	}
+	generalApi.Description = proxy.Description
+	generalApi.PlatformId = "apigee"
+	generalApi.PlatformName = "Apigee API Management"
+	if project != "" {
+		generalApi.PlatformResourceUri = "https://console.cloud.google.com/apigee/proxies/" + name + "/overview?project=" + project
+	}
+
+	// the base path of the first proxy endpoint is where the API is served
+	proxyEndpoints, _ := filepath.Glob(bundleDir + "/proxies/*.xml")
+	for _, proxyEndpointFile := range proxyEndpoints {
+		var proxyEndpoint ApigeeBundleProxyEndpoint
+		byteValue, err := os.ReadFile(proxyEndpointFile)
+		if err == nil && xml.Unmarshal(byteValue, &proxyEndpoint) == nil && generalApi.BasePath == "" {
+			generalApi.BasePath = proxyEndpoint.BasePath
+		}
+	}
+	if generalApi.BasePath == "" {
+		generalApi.BasePath = proxy.BasePaths
+	}
+	// the gateway url is only known for environments attached to a group with a hostname
+
+	targetEndpoints, _ := filepath.Glob(bundleDir + "/targets/*.xml")
+	for _, targetEndpointFile := range targetEndpoints {
+		var targetEndpoint ApigeeBundleTargetEndpoint
+		byteValue, err := os.ReadFile(targetEndpointFile)
+		if err != nil || xml.Unmarshal(byteValue, &targetEndpoint) != nil {
+			continue
+		}
+
+		if targetEndpoint.Url != "" {
+			generalApi.Backends = append(generalApi.Backends, GeneralBackend{Name: targetEndpoint.Name, Type: "http", Url: targetEndpoint.Url})
+		} else {
+			// load balanced targets reference target servers configured in the environment
+			for _, server := range targetEndpoint.Servers {
+				generalApi.Backends = append(generalApi.Backends, GeneralBackend{Name: targetEndpoint.Name, Type: "target-server", Url: targetEndpoint.Path, ConnectionId: server.Name})
+			}
+		}
+	}
+
+	generalApi.SecuritySchemes = getApigeeBundleSecuritySchemes(bundleDir)
+
+	if proxy.Revision != "" {
+		revision := GeneralRevision{Revision: proxy.Revision, IsCurrent: true}
+		if proxy.CreatedAt > 0 {
+			revision.CreatedDate = time.UnixMilli(proxy.CreatedAt).UTC().Format(time.RFC3339)
+		}
+		if proxy.LastModifiedAt > 0 {
+			revision.UpdatedDate = time.UnixMilli(proxy.LastModifiedAt).UTC().Format(time.RFC3339)
+		}
+		generalApi.Revisions = []GeneralRevision{revision}
+	}
+
+	return generalApi, nil
+}
+
+// getApigeeApiDeployment returns the deployment of a proxy to an environment, reachable from the hostnames of the environment's groups.
+func getApigeeApiDeployment(generalApi GeneralApi, apiName string, apigeeDeployment ApigeeDeployment, environmentGroups ApigeeEnvironmentGroups) GeneralApi {
+	deployment := generalApi
+	deployment.Name = apiName + "-" + apigeeDeployment.Environment + "-apigee"
+	deployment.VersionName = apiName
+	deployment.Gateway = apigeeDeployment.Environment
+	for _, environmentGroup := range environmentGroups.EnvironmentGroups {
+		if slices.Contains(environmentGroup.Environments, apigeeDeployment.Environment) && len(environmentGroup.Hostnames) > 0 {
+			deployment.GatewayUrl = "https://" + environmentGroup.Hostnames[0] + generalApi.BasePath
+			break
+		}
+	}
+
+	revision := GeneralRevision{Revision: apigeeDeployment.Revision, IsCurrent: true}
+	if len(generalApi.Revisions) > 0 && generalApi.Revisions[0].Revision == apigeeDeployment.Revision {
+		revision.CreatedDate = generalApi.Revisions[0].CreatedDate
+	}
+	if deployStartTime, err := strconv.ParseInt(apigeeDeployment.DeployStartTime, 10, 64); err == nil {
+		revision.UpdatedDate = time.UnixMilli(deployStartTime).UTC().Format(time.RFC3339)
+	}
+	deployment.Revisions = []GeneralRevision{revision}
+
+	return deployment
+}
+
+// writeApigeeGeneralDeployment writes a general deployment with the first OpenAPI spec of its bundle, preferring JSON.
+func writeApigeeGeneralDeployment(dir string, deployment GeneralApi, bundleDir string) {
+	bytes, _ := json.MarshalIndent(deployment, "", "  ")
+	os.WriteFile(dir+"/"+deployment.Name+".json", bytes, 0644)
+
+	spec := getApigeeBundleSpec(bundleDir)
+	if spec != nil {
+		os.WriteFile(dir+"/"+deployment.Name+"-oas.json", spec, 0644)
+	}
+}
+
+// getApigeeBundleSecuritySchemes returns the API key, OAuth and JWT verifications a bundle does.
+func getApigeeBundleSecuritySchemes(bundleDir string) []GeneralSecurityScheme {
+	schemes := []GeneralSecurityScheme{}
+	policyFiles, _ := filepath.Glob(bundleDir + "/policies/*.xml")
+	for _, policyFile := range policyFiles {
+		var policy ApigeeBundlePolicy
+		byteValue, err := os.ReadFile(policyFile)
+		if err != nil || xml.Unmarshal(byteValue, &policy) != nil {
+			continue
+		}
+
+		switch policy.XMLName.Local {
+		case "VerifyAPIKey":
+			schemes = append(schemes, GeneralSecurityScheme{Name: policy.Name, Type: "apiKey", IdentitySource: policy.APIKey.Ref})
+		case "OAuthV2":
+			if policy.Operation == "VerifyAccessToken" {
+				schemes = append(schemes, GeneralSecurityScheme{Name: policy.Name, Type: "oauth2"})
+			}
+		case "VerifyJWT":
+			scheme := GeneralSecurityScheme{Name: policy.Name, Type: "jwt", Issuer: policy.Issuer}
+			if policy.Audiences != "" {
+				scheme.Audiences = strings.Split(policy.Audiences, ",")
+			}
+			schemes = append(schemes, scheme)
+		}
+	}
+
+	return schemes
+}
+
+func getApigeeBundleSpec(bundleDir string) []byte {
+	var jsonSpec, yamlSpec []byte
+	filepath.WalkDir(bundleDir+"/resources", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		if strings.HasSuffix(path, ".json") && jsonSpec == nil && (gjson.GetBytes(content, "openapi").Exists() || gjson.GetBytes(content, "swagger").Exists()) {
+			jsonSpec = content
+		} else if (strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) && yamlSpec == nil && regexp.MustCompile(`(?m)^["']?(openapi|swagger)["']?:`).Match(content) {
+			yamlSpec = content
+		}
+
+		return nil
+	})
+
+	if jsonSpec != nil {
+		return jsonSpec
+	} else if yamlSpec != nil {
+		spec, err := getGeneralJsonSpec(yamlSpec)
+		if err != nil {
+			fmt.Println("  >> Could not convert the YAML spec in " + bundleDir + " to JSON, " + err.Error())
+		}
+		return spec
+	}
+
+	return nil
+}
+
+func apigeeProductsExport(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/products"
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot export Apigee products. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Exporting Apigee products for project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	products := getApigeeApiProducts(flags.Project, flags.Token)
+	os.MkdirAll(baseDir, 0755)
+	for _, product := range products.Products {
+		if flags.ApiProduct == "" || flags.ApiProduct == product.Name {
+			fmt.Println("Exporting " + product.Name + "...")
+			bytes, _ := json.MarshalIndent(product, "", "  ")
+			os.WriteFile(baseDir+"/"+product.Name+".json", bytes, 0644)
+		}
+	}
+
+	return nil
+}
+
+func apigeeProductsImport(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/products"
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot import Apigee products. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Importing Apigee products to project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	entries, _ := os.ReadDir(baseDir)
+	for _, e := range entries {
+		var product ApigeeProduct
+		byteValue, err := os.ReadFile(baseDir + "/" + e.Name())
+		if err != nil || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		json.Unmarshal(byteValue, &product)
+
+		if product.Name != "" && (flags.ApiProduct == "" || flags.ApiProduct == product.Name) {
+			fmt.Println("Importing " + product.Name + "...")
+			importApigeeProduct(flags.Project, flags.Token, product)
+		}
+	}
+
+	return nil
+}
+
+func apigeeDevelopersExport(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/developers"
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot export Apigee developers. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Exporting Apigee developers for project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	developers := getApigeeDevelopers(flags.Project, flags.Token)
+	os.MkdirAll(baseDir, 0755)
+	for _, developer := range developers.Developers {
+		if flags.DeveloperEmail == "" || flags.DeveloperEmail == developer.Email {
+			fmt.Println("Exporting " + developer.Email + "...")
+			bytes, _ := json.MarshalIndent(developer, "", "  ")
+			os.WriteFile(baseDir+"/"+developer.Email+".json", bytes, 0644)
+		}
+	}
+
+	return nil
+}
+
+func apigeeDevelopersImport(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/developers"
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot import Apigee developers. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Importing Apigee developers to project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	entries, _ := os.ReadDir(baseDir)
+	for _, e := range entries {
+		var developer ApigeeDeveloper
+		byteValue, err := os.ReadFile(baseDir + "/" + e.Name())
+		if err != nil || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		json.Unmarshal(byteValue, &developer)
+
+		if developer.Email != "" && (flags.DeveloperEmail == "" || flags.DeveloperEmail == developer.Email) {
+			fmt.Println("Importing " + developer.Email + "...")
+			importApigeeDeveloper(flags.Project, flags.Token, developer)
+		}
+	}
+
+	return nil
+}
+
+func apigeeAppsExport(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/apps"
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot export Apigee apps. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Exporting Apigee apps for project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	developers := getApigeeDevelopers(flags.Project, flags.Token)
+	for _, developer := range developers.Developers {
+		if flags.DeveloperEmail != "" && flags.DeveloperEmail != developer.Email {
+			continue
+		}
+
+		apps := getApigeeDeveloperApps(flags.Project, flags.Token, developer.Email)
+		for _, app := range apps.Apps {
+			fmt.Println("Exporting " + developer.Email + " app " + app.Name + "...")
+
+			// the products are taken from the keys, keys and secrets are not exported, importing creates new ones
+			app.DeveloperEmail = developer.Email
+			app.ApiProducts = []string{}
+			for _, credential := range app.Credentials {
+				for _, apiProduct := range credential.ApiProducts {
+					if !slices.Contains(app.ApiProducts, apiProduct.ApiProduct) {
+						app.ApiProducts = append(app.ApiProducts, apiProduct.ApiProduct)
+					}
+				}
+			}
+			app.Credentials = nil
+
+			bytes, _ := json.MarshalIndent(app, "", "  ")
+			os.MkdirAll(baseDir+"/"+developer.Email, 0755)
+			os.WriteFile(baseDir+"/"+developer.Email+"/"+app.Name+".json", bytes, 0644)
+		}
+	}
+
+	return nil
+}
+
+func apigeeAppsImport(flags *ApigeeFlags) error {
+	baseDir := "src/main/apigee/apps"
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot import Apigee apps. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Importing Apigee apps to project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	developerEntries, _ := os.ReadDir(baseDir)
+	for _, d := range developerEntries {
+		if !d.IsDir() || (flags.DeveloperEmail != "" && flags.DeveloperEmail != d.Name()) {
+			continue
+		}
+
+		entries, _ := os.ReadDir(baseDir + "/" + d.Name())
+		for _, e := range entries {
+			var app ApigeeDeveloperApp
+			byteValue, err := os.ReadFile(baseDir + "/" + d.Name() + "/" + e.Name())
+			if err != nil || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			json.Unmarshal(byteValue, &app)
+			if app.DeveloperEmail == "" {
+				app.DeveloperEmail = d.Name()
+			}
+
+			if app.Name != "" {
+				fmt.Println("Importing " + app.DeveloperEmail + " app " + app.Name + "...")
+				importApigeeDeveloperApp(flags.Project, flags.Token, app)
+			}
+		}
+	}
+
+	return nil
+}
+
+// importApigeeProduct creates a product, or updates it if it already exists.
+func importApigeeProduct(org string, token string, product ApigeeProduct) bool {
+	body, _ := json.Marshal(product)
+	return importApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/apiproducts", product.Name, body, token)
+}
+
+// importApigeeDeveloper creates a developer, or updates it if it already exists.
+func importApigeeDeveloper(org string, token string, developer ApigeeDeveloper) bool {
+	body, _ := json.Marshal(developer)
+	return importApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/developers", developer.Email, body, token)
+}
+
+// importApigeeDeveloperApp creates an app, or updates it and adds missing products to its first key if it already exists.
+func importApigeeDeveloperApp(org string, token string, app ApigeeDeveloperApp) bool {
+	app.Credentials = nil
+	// Apigee X has no expiry type, keys that never expire have a key expiry of -1
+	if app.ExpiryType == "never" && app.KeyExpiresIn == "" {
+		app.KeyExpiresIn = "-1"
+	}
+	app.ExpiryType = ""
+	body, _ := json.Marshal(app)
+	appsUrl := "https://apigee.googleapis.com/v1/organizations/" + org + "/developers/" + app.DeveloperEmail + "/apps"
+	if !importApigeeResource(appsUrl, app.Name, body, token) {
+		return false
+	}
+
+	// updates don't change the products of the keys
+	existingApp := getApigeeDeveloperApp(org, token, app.DeveloperEmail, app.Name)
+	if len(existingApp.Credentials) == 0 {
+		return true
+	}
+	credential := existingApp.Credentials[0]
+	missingProducts := []string{}
+	for _, apiProduct := range app.ApiProducts {
+		if !slices.ContainsFunc(credential.ApiProducts, func(p ApigeeCredentialProduct) bool { return p.ApiProduct == apiProduct }) {
+			missingProducts = append(missingProducts, apiProduct)
+		}
+	}
+	if len(missingProducts) > 0 {
+		body, _ := json.Marshal(map[string][]string{"apiProducts": missingProducts})
+		return sendApigeeResource(http.MethodPost, appsUrl+"/"+app.Name+"/keys/"+credential.ConsumerKey, body, token)
+	}
+
+	return true
+}
+
+// importApigeeResource creates a resource in a collection, and replaces it if it already exists.
+func importApigeeResource(collectionUrl string, name string, body []byte, token string) bool {
+	req, _ := http.NewRequest(http.MethodPost, collectionUrl, bytes.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 409 {
+		return sendApigeeResource(http.MethodPut, collectionUrl+"/"+name, body, token)
+	} else if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		fmt.Println("  >> " + resp.Status + " " + string(responseBody))
+		return false
+	}
+
+	return true
+}
+
+func sendApigeeResource(method string, resourceUrl string, body []byte, token string) bool {
+	req, _ := http.NewRequest(method, resourceUrl, bytes.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		fmt.Println("  >> " + resp.Status + " " + string(responseBody))
+		return false
+	}
+
+	return true
+}
+
+func apigeeSharedFlowsExport(flags *ApigeeFlags) error {
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot export Apigee shared flows. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Exporting Apigee shared flows for project " + flags.Project + "...")
+	var baseDir = "src/main/apigee/sharedflows"
+
+	var environment ApigeeEnvironment
+	if flags.Environment != "" {
+		os.MkdirAll("src/main/apigee/environments/"+flags.Environment, 0755)
+		environment = ApigeeEnvironment{Proxies: []ApigeeEnvironmentProxy{}, SharedFlows: []ApigeeEnvironmentProxy{}}
+		byteValue, err := os.ReadFile("src/main/apigee/environments/" + flags.Environment + "/deployments.json")
+		if err == nil {
+			json.Unmarshal(byteValue, &environment)
+		}
+	}
+
+	flags.Token = getApigeeToken(flags)
+
+	sharedFlows := getApigeeSharedFlows(flags.Project, flags.Token)
+	os.MkdirAll(baseDir, 0755)
+	for _, sharedFlow := range sharedFlows.SharedFlows {
+		if flags.ApiName != "" && flags.ApiName != sharedFlow.Name {
+			continue
+		}
+
+		revision := getApigeeLatestRevision(flags.Project, flags.Token, "sharedflows", sharedFlow.Name)
+		fmt.Println("Exporting " + sharedFlow.Name + " revision " + revision + "...")
+		bundle := getApigeeSharedFlowBundle(flags.Project, sharedFlow.Name, revision, flags.Token)
+		if bundle == nil {
+			continue
+		}
+
+		os.RemoveAll(baseDir + "/" + sharedFlow.Name)
+		os.WriteFile(baseDir+"/"+sharedFlow.Name+".zip", bundle, 0644)
+		unzipApigeeBundle(baseDir, sharedFlow.Name)
+		os.Remove(baseDir + "/" + sharedFlow.Name + ".zip")
+
+		if !slices.ContainsFunc(environment.SharedFlows, func(flow ApigeeEnvironmentProxy) bool { return flow.Name == sharedFlow.Name }) {
+			environment.SharedFlows = append(environment.SharedFlows, ApigeeEnvironmentProxy{Name: sharedFlow.Name})
+		}
+	}
+
+	if flags.Environment != "" {
+		bytes, _ := json.MarshalIndent(environment, "", "  ")
+		os.WriteFile("src/main/apigee/environments/"+flags.Environment+"/deployments.json", bytes, 0644)
+	}
+
+	return nil
+}
+
+func apigeeSharedFlowsImport(flags *ApigeeFlags) error {
+	if flags.Project == "" {
+		fmt.Println("No project given. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Importing Apigee shared flows to project " + flags.Project + "...")
+	var baseDir = "src/main/apigee/sharedflows"
+	flags.Token = getApigeeToken(flags)
+
+	for _, name := range getApigeeSharedFlowOrder(baseDir, getApigeeLocalSharedFlows(baseDir, flags.ApiName)) {
+		fmt.Println("Importing " + name + "...")
+		os.Chdir(baseDir + "/" + name)
+		zipApigeeBundleDir(name, "sharedflowbundle")
+		err := createApigeeBundle(flags.Project, flags.Token, "sharedflows", name)
+		if err != nil {
+			fmt.Println("Error importing Apigee shared flow: " + err.Error())
+		}
+		os.Remove(name + ".zip")
+		os.Chdir("../../../../..")
+	}
+
+	return nil
+}
+
+func apigeeSharedFlowsDeploy(flags *ApigeeFlags) error {
+	if flags.Project == "" {
+		fmt.Println("No project given. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	} else if flags.Environment == "" {
+		fmt.Println("No Apigee environment given. Please specify an --environment YOUR_ENVIRONMENT flag.")
+		return nil
+	}
+
+	fmt.Println("Deploying Apigee shared flows to project " + flags.Project + "...")
+	var baseDir = "src/main/apigee/sharedflows"
+	flags.Token = getApigeeToken(flags)
+
+	deployApigeeSharedFlows(flags, getApigeeSharedFlowOrder(baseDir, getApigeeLocalSharedFlows(baseDir, flags.ApiName)))
+
+	return nil
+}
+
+func apigeeSharedFlowsClean(flags *ApigeeFlags) error {
+	if flags.Project == "" {
+		fmt.Println("No project given. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	fmt.Println("Removing all Apigee shared flows for project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	sharedFlows := getApigeeSharedFlows(flags.Project, flags.Token)
+	for _, sharedFlow := range sharedFlows.SharedFlows {
+		if flags.ApiName == "" || flags.ApiName == sharedFlow.Name {
+			fmt.Println("Deleting " + sharedFlow.Name + "...")
+			deleteApigeeSharedFlow(flags.Project, flags.Token, sharedFlow.Name)
+		}
+	}
+
+	return nil
+}
+
+// deployApigeeSharedFlows deploys the latest revisions of shared flows in order, waiting for each one so the next can call it.
+func deployApigeeSharedFlows(flags *ApigeeFlags, names []string) {
+	for _, name := range names {
+		latestVersion := getApigeeLatestRevision(flags.Project, flags.Token, "sharedflows", name)
+		if latestVersion == "" {
+			fmt.Println("  >> Shared flow " + name + " was not found, import it first.")
+			continue
+		}
+
+		fmt.Println("Deploying shared flow " + name + " version " + latestVersion + " to environment " + flags.Environment + "...")
+		if deployApigeeBundle(flags.Project, flags.Token, flags.Environment, "sharedflows", name, latestVersion, flags.ServiceAccount) != nil {
+			continue
+		}
+
+		for i := 0; i < 30; i++ {
+			state := getApigeeDeploymentState(flags.Project, flags.Token, flags.Environment, "sharedflows", name, latestVersion)
+			if state == "READY" || state == "ERROR" {
+				if state == "ERROR" {
+					fmt.Println("  >> Shared flow " + name + " failed to deploy.")
+				}
+				break
+			}
+			time.Sleep(2 * time.Second)
+		}
+	}
+}
+
+func getApigeeLocalSharedFlows(baseDir string, name string) []string {
+	names := []string{}
+	entries, _ := os.ReadDir(baseDir)
+	for _, e := range entries {
+		if e.IsDir() && (name == "" || name == e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+
+	return names
+}
+
+// getApigeeSharedFlowOrder returns the local shared flows with the local shared flows they call, each after the ones it calls.
+func getApigeeSharedFlowOrder(baseDir string, names []string) []string {
+	result := []string{}
+	visited := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		for _, dependency := range getApigeeBundleFlowCallouts(baseDir + "/" + name + "/sharedflowbundle") {
+			if _, err := os.Stat(baseDir + "/" + dependency + "/sharedflowbundle"); err == nil {
+				visit(dependency)
+			}
+		}
+		result = append(result, name)
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return result
+}
+
+// getApigeeBundleFlowCallouts returns the shared flows that the FlowCallout policies of a bundle call.
+func getApigeeBundleFlowCallouts(bundleDir string) []string {
+	sharedFlows := []string{}
+	policyFiles, _ := filepath.Glob(bundleDir + "/policies/*.xml")
+	for _, policyFile := range policyFiles {
+		var policy struct {
+			XMLName          xml.Name
+			SharedFlowBundle string `xml:"SharedFlowBundle"`
+		}
+		byteValue, err := os.ReadFile(policyFile)
+		if err != nil || xml.Unmarshal(byteValue, &policy) != nil {
+			continue
+		}
+
+		sharedFlow := strings.TrimSpace(policy.SharedFlowBundle)
+		if policy.XMLName.Local == "FlowCallout" && sharedFlow != "" && !slices.Contains(sharedFlows, sharedFlow) {
+			sharedFlows = append(sharedFlows, sharedFlow)
+		}
+	}
+
+	return sharedFlows
+}
+
+func getApigeeSharedFlows(org string, token string) ApigeeSharedFlows {
+	var result ApigeeSharedFlows
+	json.Unmarshal(getApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/sharedflows", token), &result)
+
+	return result
+}
+
+func getApigeeSharedFlowBundle(org string, name string, revision string, token string) []byte {
+	return getApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/sharedflows/"+name+"/revisions/"+revision+"?format=bundle", token)
+}
+
+func getApigeeDeploymentState(org string, token string, env string, collection string, name string, revision string) string {
+	body := getApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/environments/"+env+"/"+collection+"/"+name+"/revisions/"+revision+"/deployments", token)
+	return gjson.GetBytes(body, "state").String()
+}
+
+func deleteApigeeSharedFlow(org string, token string, name string) {
+	if !sendApigeeResource(http.MethodDelete, "https://apigee.googleapis.com/v1/organizations/"+org+"/sharedflows/"+name, nil, token) {
+		fmt.Println("Error deleting Apigee shared flow " + name + ".")
+	}
+}
+
+func getApigeeApis(org string, token string) ApigeeProxies {
+	var apis ApigeeProxies
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis?includeRevisions=true", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			json.Unmarshal(body, &apis)
+			//fmt.Println(string(body))
+		}
+	}
+
+	return apis
+}
+
+func getApigeeDeployments(org string, token string) ApigeeDeployments {
+	var result ApigeeDeployments
+	json.Unmarshal(getApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/deployments", token), &result)
+
+	return result
+}
+
+// getApigeeEnvironmentGroups returns the environment groups of an org, with their attached environments.
+func getApigeeEnvironmentGroups(org string, token string) ApigeeEnvironmentGroups {
+	var result ApigeeEnvironmentGroups
+	json.Unmarshal(getApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/envgroups", token), &result)
+
+	for i, environmentGroup := range result.EnvironmentGroups {
+		var attachments ApigeeEnvironmentGroupAttachments
+		json.Unmarshal(getApigeeResource("https://apigee.googleapis.com/v1/organizations/"+org+"/envgroups/"+environmentGroup.Name+"/attachments", token), &attachments)
+
+		for _, attachment := range attachments.EnvironmentGroupAttachments {
+			result.EnvironmentGroups[i].Environments = append(result.EnvironmentGroups[i].Environments, attachment.Environment)
+		}
+	}
+
+	return result
+}
+
+// getApigeeResource returns the body of an Apigee resource, or nil and prints the status if it could not be read.
+func getApigeeResource(resourceUrl string, token string) []byte {
+	req, _ := http.NewRequest(http.MethodGet, resourceUrl, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	} else if resp.StatusCode != 200 {
+		fmt.Println("  >> " + resp.Status + " " + string(body))
+		return nil
+	}
+
+	return body
+}
+
+func getApigeeApiProducts(org string, token string) ApigeeProducts {
+	var result ApigeeProducts
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apiproducts?expand=true", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			json.Unmarshal(body, &result)
+			//fmt.Println(string(body))
+		}
+	}
+
+	return result
+}
+
+func getApigeeDevelopers(org string, token string) ApigeeDevelopers {
+	var result ApigeeDevelopers
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/developers?expand=true", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			json.Unmarshal(body, &result)
+			//fmt.Println(string(body))
+		}
+	}
+
+	return result
+}
+
+func getApigeeDeveloperApps(org string, token string, email string) ApigeeDeveloperApps {
+	var result ApigeeDeveloperApps
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/developers/"+email+"/apps?expand=true", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			json.Unmarshal(body, &result)
+		}
+	}
+
+	return result
+}
+
+func getApigeeDeveloperApp(org string, token string, email string, name string) ApigeeDeveloperApp {
+	var result ApigeeDeveloperApp
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/developers/"+email+"/apps/"+name, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && resp.StatusCode == 200 {
+			json.Unmarshal(body, &result)
+		}
+	}
+
+	return result
+}
+
+func getApigeeApiBundle(org string, api string, revision string, token string) []byte {
+	var bundle []byte
+
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis/"+api+"/revisions/"+revision+"?format=bundle", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		bundle, _ = io.ReadAll(resp.Body)
+	}
+
+	return bundle
+}
+
+func unzipApigeeBundle(basePath string, name string) {
+	zipBaseBath := basePath + "/" + name
+	os.Mkdir(basePath+"/"+name, 0755)
+	archive, err := zip.OpenReader(basePath + "/" + name + ".zip")
+	if err != nil {
+		panic(err)
+	}
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		filePath := filepath.Join(zipBaseBath, f.Name)
+
+		if !strings.HasPrefix(filePath, filepath.Clean(zipBaseBath)+string(os.PathSeparator)) {
+			fmt.Println("invalid file path")
+			return
+		}
+		if f.FileInfo().IsDir() {
+			os.MkdirAll(filePath, os.ModePerm)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			panic(err)
+		}
+
+		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		if err != nil {
+			panic(err)
+		}
+
+		fileInArchive, err := f.Open()
+		if err != nil {
+			panic(err)
+		}
+
+		if _, err := io.Copy(dstFile, fileInArchive); err != nil {
+			panic(err)
+		}
+
+		dstFile.Close()
+		fileInArchive.Close()
+	}
+}
+
+func zipApigeeBundle(name string) {
+	zipApigeeBundleDir(name, "apiproxy")
+}
+
+// zipApigeeBundleDir zips a bundle root directory, apiproxy or sharedflowbundle, in the working directory.
+func zipApigeeBundleDir(name string, bundleDir string) {
+	file, err := os.Create(name + ".zip")
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	defer w.Close()
+
+	walker := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// Ensure that `path` is not absolute; it should not start with "/".
+		// This snippet happens to work because I don't use
+		// absolute paths, but ensure your real-world code
+		// transforms path into a zip-root relative path.
+		f, err := w.Create(path)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(f, file)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	err = filepath.Walk(bundleDir, walker)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func deleteApigeeApi(org string, token string, api string) {
+	req, _ := http.NewRequest(http.MethodDelete, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis/"+api, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	_, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("Error deleting Apigee API: " + err.Error())
+	}
+}
+
+func createApigeeApi(org string, token string, name string) error {
+	return createApigeeBundle(org, token, "apis", name)
+}
+
+// createApigeeBundle imports the zipped bundle in the working directory as a new revision of a proxy (apis) or shared flow (sharedflows).
+func createApigeeBundle(org string, token string, collection string, name string) error {
+
+	fileDir, _ := os.Getwd()
+	fileName := name + ".zip"
+	filePath := path.Join(fileDir, fileName)
+
+	file, _ := os.Open(filePath)
+	defer file.Close()
+
+	//fmt.Println(filepath.Base(file.Name()))
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filepath.Base(file.Name()))
+	io.Copy(part, file)
+	writer.Close()
+
+	r, _ := http.NewRequest(http.MethodPost, "https://apigee.googleapis.com/v1/organizations/"+org+"/"+collection+"?name="+name+"&action=import", body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+	r.Header.Add("Authorization", "Bearer "+token)
+	client := &http.Client{}
+	resp, err := client.Do(r)
+
+	if err == nil && resp.StatusCode != 200 {
+		fmt.Println("Error creating Apigee bundle: " + resp.Status)
+	}
+
+	return err
+}
+
+func deployApigeeApi(org string, token string, env string, name string, version string, serviceAccount string) error {
+	return deployApigeeBundle(org, token, env, "apis", name, version, serviceAccount)
+}
+
+func deployApigeeBundle(org string, token string, env string, collection string, name string, version string, serviceAccount string) error {
+
+	url := "https://apigee.googleapis.com/v1/organizations/" + org + "/environments/" + env + "/" + collection + "/" + name + "/revisions/" + version + "/deployments?override=true"
+	if serviceAccount != "" {
+		url = url + "&serviceAccount=" + serviceAccount
+	}
+
+	r, _ := http.NewRequest(http.MethodPost, url, nil)
 	r.Header.Add("Content-Type", "application/json")
 	r.Header.Add("Authorization", "Bearer "+token)
 	client := &http.Client{}
 	resp, err := client.Do(r)
 
-	if resp.StatusCode != 200 {
-		fmt.Println("Error deploying Apigee API: " + resp.Status)
+	if err == nil && resp.StatusCode != 200 {
+		fmt.Println("Error deploying Apigee bundle: " + resp.Status)
 		body, err := io.ReadAll(resp.Body)
 		if err == nil {
 			fmt.Println(string(body))
@@ -650,9 +1950,13 @@ func deployApigeeApi(org string, token string, env string, name string, version
 }
 
 func getApigeeApiLatestVersion(org string, token string, name string) string {
+	return getApigeeLatestRevision(org, token, "apis", name)
+}
+
+func getApigeeLatestRevision(org string, token string, collection string, name string) string {
 	var result string
 	var apigeeApi ApigeeApi
-	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/apis/"+name, nil)
+	req, _ := http.NewRequest(http.MethodGet, "https://apigee.googleapis.com/v1/organizations/"+org+"/"+collection+"/"+name, nil)
 	req.Header.Add("Authorization", "Bearer "+token)
 
 	resp, err := http.DefaultClient.Do(req)
@@ -686,6 +1990,16 @@ func deleteApigeeDeveloper(org string, token string, email string) {
 	}
 }
 
+func deleteApigeeDeveloperApp(org string, token string, email string, name string) {
+	req, _ := http.NewRequest(http.MethodDelete, "https://apigee.googleapis.com/v1/organizations/"+org+"/developers/"+email+"/apps/"+name, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	_, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("Error deleting Apigee developer app: " + err.Error())
+	}
+}
+
 func deleteApigeeProduct(org string, token string, name string) {
 	req, _ := http.NewRequest(http.MethodDelete, "https://apigee.googleapis.com/v1/organizations/"+org+"/apiproducts/"+name, nil)
 	req.Header.Add("Authorization", "Bearer "+token)
@@ -751,3 +2065,133 @@ func initApigeeTest(flags *ApigeeFlags) error {
 
 	return nil
 }
+
+func applyApigeeTest(flags *ApigeeFlags) error {
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot apply test data. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	if flags.Environment == "" {
+		fmt.Println("No environment given, cannot apply test data. Please specify an environment with the --environment YOUR_ENVIRONMENT flag.")
+		return nil
+	}
+
+	developers, products, apps, err := getApigeeTestData(flags.Environment)
+	if err != nil {
+		fmt.Println("No test data found for environment " + flags.Environment + ", run apigee test init first.")
+		return nil
+	}
+
+	fmt.Println("Applying test data for environment " + flags.Environment + " to project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	// products, developers and then apps, since apps reference both
+	for _, product := range products {
+		if !slices.Contains(product.Environments, flags.Environment) {
+			product.Environments = append(product.Environments, flags.Environment)
+		}
+		fmt.Println("Applying product " + product.Name + "...")
+		importApigeeProduct(flags.Project, flags.Token, product)
+	}
+
+	for _, developer := range developers {
+		fmt.Println("Applying developer " + developer.Email + "...")
+		importApigeeDeveloper(flags.Project, flags.Token, developer)
+	}
+
+	for _, app := range apps {
+		fmt.Println("Applying app " + app.Name + "...")
+		if !importApigeeDeveloperApp(flags.Project, flags.Token, app) {
+			continue
+		}
+
+		// keys are generated asynchronously, wait until the app has one
+		var consumerKey string
+		for i := 0; i < 10 && consumerKey == ""; i++ {
+			existingApp := getApigeeDeveloperApp(flags.Project, flags.Token, app.DeveloperEmail, app.Name)
+			if len(existingApp.Credentials) > 0 {
+				consumerKey = existingApp.Credentials[0].ConsumerKey
+			} else {
+				time.Sleep(2 * time.Second)
+			}
+		}
+
+		if consumerKey != "" {
+			fmt.Println("App " + app.Name + " consumer key: " + consumerKey)
+		} else {
+			fmt.Println("  >> No key was generated for app " + app.Name + ".")
+		}
+	}
+
+	return nil
+}
+
+func teardownApigeeTest(flags *ApigeeFlags) error {
+	if flags.Project == "" {
+		fmt.Println("No project given, cannot tear down test data. Please specify a --project YOUR_PROJECT_ID flag.")
+		return nil
+	}
+
+	if flags.Environment == "" {
+		fmt.Println("No environment given, cannot tear down test data. Please specify an environment with the --environment YOUR_ENVIRONMENT flag.")
+		return nil
+	}
+
+	developers, products, apps, err := getApigeeTestData(flags.Environment)
+	if err != nil {
+		fmt.Println("No test data found for environment " + flags.Environment + ", nothing to tear down.")
+		return nil
+	}
+
+	fmt.Println("Removing test data for environment " + flags.Environment + " from project " + flags.Project + "...")
+
+	flags.Token = getApigeeToken(flags)
+
+	// in reverse order, products can't be deleted while apps use them
+	for _, app := range apps {
+		fmt.Println("Deleting app " + app.Name + "...")
+		deleteApigeeDeveloperApp(flags.Project, flags.Token, app.DeveloperEmail, app.Name)
+	}
+
+	for _, developer := range developers {
+		fmt.Println("Deleting developer " + developer.Email + "...")
+		deleteApigeeDeveloper(flags.Project, flags.Token, developer.Email)
+	}
+
+	for _, product := range products {
+		fmt.Println("Deleting product " + product.Name + "...")
+		deleteApigeeProduct(flags.Project, flags.Token, product.Name)
+	}
+
+	return nil
+}
+
+// getApigeeTestData reads the developers, products and apps written by test init for an environment.
+func getApigeeTestData(env string) ([]ApigeeDeveloper, []ApigeeProduct, []ApigeeDeveloperApp, error) {
+	var developers []ApigeeDeveloper
+	var products []ApigeeProduct
+	var apps []ApigeeDeveloperApp
+	testDir := "src/main/apigee/tests/" + env
+
+	byteValue, err := os.ReadFile(testDir + "/developers.json")
+	if err != nil {
+		return developers, products, apps, err
+	}
+	json.Unmarshal(byteValue, &developers)
+
+	byteValue, err = os.ReadFile(testDir + "/products.json")
+	if err != nil {
+		return developers, products, apps, err
+	}
+	json.Unmarshal(byteValue, &products)
+
+	byteValue, err = os.ReadFile(testDir + "/developerapps.json")
+	if err != nil {
+		return developers, products, apps, err
+	}
+	json.Unmarshal(byteValue, &apps)
+
+	return developers, products, apps, nil
+}
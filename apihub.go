@@ -23,12 +23,31 @@ type HubApis struct {
 }
 
 type HubApi struct {
-	Name          string               `json:"name"`
-	DisplayName   string               `json:"displayName"`
-	Description   string               `json:"description"`
-	Documentation *HubApiDocumentation `json:"documentation,omitempty"`
-	Owner         *HubApiOwner         `json:"owner,omitempty"`
-	Versions      *[]string            `json:"versions,omitempty"`
+	Name          string                              `json:"name"`
+	DisplayName   string                              `json:"displayName"`
+	Description   string                              `json:"description"`
+	Documentation *HubApiDocumentation                `json:"documentation,omitempty"`
+	Owner         *HubApiOwner                        `json:"owner,omitempty"`
+	Versions      *[]string                           `json:"versions,omitempty"`
+	Attributes    map[string]HubAttributeStringValues `json:"attributes,omitempty"`
+}
+
+type HubAttributeStringValues struct {
+	Attribute    string          `json:"attribute"`
+	StringValues HubStringValues `json:"stringValues"`
+}
+
+type HubStringValues struct {
+	Values []string `json:"values"`
+}
+
+type HubAttributeDefinition struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Scope       string `json:"scope"`
+	DataType    string `json:"dataType"`
+	Cardinality int    `json:"cardinality"`
 }
 
 type HubApiDocumentation struct {
@@ -190,6 +209,27 @@ func apiHubOnramp(flags *ApigeeFlags) error {
 		log.Fatal(err)
 	}
 
+	// products are recorded as an attribute on each API they include
+	apiProducts := make(map[string][]string)
+	for _, product := range getGeneralProducts() {
+		for _, api := range product.Apis {
+			apiProducts[api] = append(apiProducts[api], product.DisplayName)
+		}
+	}
+	if len(apiProducts) > 0 {
+		var attribute HubAttributeDefinition
+		attribute.Name = "projects/" + flags.Project + "/locations/" + flags.Region + "/attributes/products"
+		attribute.DisplayName = "Products"
+		attribute.Description = "The products and plans that include this API."
+		attribute.Scope = "API"
+		attribute.DataType = "STRING"
+		attribute.Cardinality = 20
+
+		bytes, _ := json.MarshalIndent(attribute, "", "  ")
+		os.MkdirAll("src/main/apihub/attributes", 0755)
+		os.WriteFile("src/main/apihub/attributes/products.json", bytes, 0644)
+	}
+
 	for _, e := range entries {
 		if flags.ApiName == "" || flags.ApiName == e.Name() {
 			fmt.Println(e.Name())
@@ -228,18 +268,23 @@ func apiHubOnramp(flags *ApigeeFlags) error {
 					hubApi.Owner = &owner
 				}
 
+				if products, ok := apiProducts[apiName]; ok {
+					attributeName := "projects/" + flags.Project + "/locations/" + flags.Region + "/attributes/products"
+					hubApi.Attributes = map[string]HubAttributeStringValues{attributeName: {Attribute: attributeName, StringValues: HubStringValues{Values: products}}}
+				}
+
 				bytes, _ := json.MarshalIndent(hubApi, "", "  ")
 				os.WriteFile(baseDir+"/"+apiName+"/"+apiName+".json", bytes, 0644)
 
 				var apiVersions map[string][]HubApiDeployment = make(map[string][]HubApiDeployment)
+				var apiVersionChangelogs map[string]string = make(map[string]string)
 
 				// read all files
 				fileEntries, _ := os.ReadDir(generalBaseDir + "/" + e.Name())
 				for _, f := range fileEntries {
-					if strings.HasSuffix(f.Name(), "-aws.json") || strings.HasSuffix(f.Name(), "-azure.json") {
+					if isGeneralDeploymentFile(f.Name()) {
 						fmt.Println(f.Name())
-						apiVersionName := strings.ReplaceAll(f.Name(), "-aws.json", "")
-						apiVersionName = strings.ReplaceAll(apiVersionName, "-azure.json", "")
+						apiVersionName := getGeneralVersionName(f.Name())
 
 						// create deployment
 						var generalDeploymentApi GeneralApi
@@ -255,11 +300,19 @@ func apiHubOnramp(flags *ApigeeFlags) error {
 
 						if generalDeploymentApi.Name != "" {
 							fmt.Println(generalDeploymentApi.Name)
+							if generalDeploymentApi.VersionName != "" {
+								apiVersionName = generalDeploymentApi.VersionName
+							}
 
 							// create deployment
 							var hubApiDeployment HubApiDeployment
 							hubApiDeployment.Name = "projects/" + flags.Project + "/locations/" + flags.Region + "/deployments/" + generalDeploymentApi.Name
 							hubApiDeployment.DisplayName = generalDeploymentApi.DisplayName
+							if generalDeploymentApi.Gateway != "" {
+								hubApiDeployment.DisplayName = hubApiDeployment.DisplayName + " (" + generalDeploymentApi.Gateway + ")"
+							} else if generalDeploymentApi.Region != "" {
+								hubApiDeployment.DisplayName = hubApiDeployment.DisplayName + " (" + generalDeploymentApi.Region + ")"
+							}
 							hubApiDeployment.Description = generalDeploymentApi.Description
 							hubApiDeployment.Documentation.ExternalUri = generalDeploymentApi.DocumentationUrl
 							hubApiDeployment.DeploymentType.Attribute = "projects/" + flags.Project + "/locations/" + flags.Region + "/attributes/system-deployment-type"
@@ -279,20 +332,27 @@ func apiHubOnramp(flags *ApigeeFlags) error {
 								apiVersions[apiVersionName] = []HubApiDeployment{hubApiDeployment}
 							}
 
-							// create API spec, if available
-							b, err := os.ReadFile(generalBaseDir + "/" + apiName + "/" + generalDeploymentApi.Name + "-oas.json")
-							if err == nil {
-								// we have a spec file
-								var hubApiVersionSpec HubApiVersionSpec
-								hubApiVersionSpec.Name = "projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + apiName + "/versions/" + apiVersionName + "/specs/" + generalDeploymentApi.Name
-								hubApiVersionSpec.DisplayName = generalDeploymentApi.DisplayName + " (" + generalDeploymentApi.PlatformName + ")"
-								apiSpecType := HubAttributeValue{Id: "openapi", DisplayName: "OpenAPI Spec", Description: "OpenAPI Spec", Immutable: true}
-								hubApiVersionSpec.SpecType.EnumValues.Values = append(hubApiVersionSpec.SpecType.EnumValues.Values, apiSpecType)
-								hubApiVersionSpec.Contents.MimeType = "application/json"
-								hubApiVersionSpec.Contents.Contents = b64.StdEncoding.EncodeToString(b)
-								hubApiVersionSpec.Documentation.ExternalUri = generalApi.DocumentationUrl
-								bytes, _ = json.MarshalIndent(hubApiVersionSpec, "", "  ")
-								os.WriteFile(baseDir+"/"+apiName+"/"+generalDeploymentApi.Name+"-oas.json", bytes, 0644)
+							// record revision history for version
+							if len(generalDeploymentApi.Revisions) > 0 {
+								apiVersionChangelogs[apiVersionName] = getGeneralChangelog(generalDeploymentApi.Revisions)
+							}
+
+							// create API specs, if available
+							for _, format := range generalSpecFormats {
+								b, err := os.ReadFile(generalBaseDir + "/" + apiName + "/" + generalDeploymentApi.Name + format.Suffix)
+								if err == nil {
+									// we have a spec file
+									var hubApiVersionSpec HubApiVersionSpec
+									hubApiVersionSpec.Name = "projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + apiName + "/versions/" + apiVersionName + "/specs/" + getApiHubSpecId(generalDeploymentApi.Name, format)
+									hubApiVersionSpec.DisplayName = generalDeploymentApi.DisplayName + " (" + generalDeploymentApi.PlatformName + ")"
+									apiSpecType := HubAttributeValue{Id: format.SpecType, DisplayName: format.DisplayName, Description: format.DisplayName, Immutable: format.SpecType == "openapi"}
+									hubApiVersionSpec.SpecType.EnumValues.Values = append(hubApiVersionSpec.SpecType.EnumValues.Values, apiSpecType)
+									hubApiVersionSpec.Contents.MimeType = format.MimeType
+									hubApiVersionSpec.Contents.Contents = b64.StdEncoding.EncodeToString(b)
+									hubApiVersionSpec.Documentation.ExternalUri = generalApi.DocumentationUrl
+									bytes, _ = json.MarshalIndent(hubApiVersionSpec, "", "  ")
+									os.WriteFile(baseDir+"/"+apiName+"/"+getApiHubSpecFile(generalDeploymentApi.Name, format), bytes, 0644)
+								}
 							}
 						}
 					}
@@ -304,6 +364,9 @@ func apiHubOnramp(flags *ApigeeFlags) error {
 					hubApiVersion.Name = "projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + apiName + "/versions/" + k
 					hubApiVersion.DisplayName = v[0].DisplayName
 					hubApiVersion.Description = generalApi.Description
+					if changelog, ok := apiVersionChangelogs[k]; ok {
+						hubApiVersion.Description = strings.TrimSpace(hubApiVersion.Description + "\n\nRevisions:\n" + changelog)
+					}
 					hubApiVersion.Documentation.ExternalUri = generalApi.DocumentationUrl
 
 					for _, d := range v {
@@ -349,6 +412,29 @@ func apiHubImport(flags *ApigeeFlags) error {
 		}
 	}
 
+	// create attributes first, APIs reference them
+	attributes, _ := os.ReadDir("src/main/apihub/attributes")
+	for _, a := range attributes {
+		byteValue, err := os.ReadFile("src/main/apihub/attributes/" + a.Name())
+		if err == nil {
+			attributeName := strings.ReplaceAll(a.Name(), ".json", "")
+			requestBody := bytes.NewBuffer(byteValue)
+			r, _ := http.NewRequest(http.MethodPost, "https://apihub.googleapis.com/v1/projects/"+flags.Project+"/locations/"+flags.Region+"/attributes?attributeId="+attributeName, requestBody)
+			r.Header.Add("Content-Type", "application/json")
+			r.Header.Add("Authorization", "Bearer "+flags.Token)
+			client := &http.Client{}
+			fmt.Println("Creating attribute " + attributeName + "...")
+			resp, err := client.Do(r)
+
+			if err == nil && resp.StatusCode != 200 && resp.StatusCode != 409 {
+				fmt.Println("  >> Error creating attribute " + attributeName + ": " + resp.Status)
+				respBody, _ := io.ReadAll(resp.Body)
+				fmt.Println(string(respBody))
+				defer resp.Body.Close()
+			}
+		}
+	}
+
 	apis, err := os.ReadDir(baseDir)
 	if err == nil {
 		for _, e := range apis {
@@ -383,13 +469,16 @@ func apiHubImport(flags *ApigeeFlags) error {
 				defer apiFile.Close()
 
 				var apiVersions map[string][]string = make(map[string][]string)
+				deploymentVersions := getApiHubLocalDeploymentVersions(baseDir + "/" + e.Name())
 				// read all files
 				fileEntries, _ := os.ReadDir(baseDir + "/" + e.Name())
 				for _, f := range fileEntries {
-					if strings.HasSuffix(f.Name(), "-aws.json") || strings.HasSuffix(f.Name(), "-azure.json") {
+					if isGeneralDeploymentFile(f.Name()) {
 						apiDeploymentName := strings.ReplaceAll(f.Name(), ".json", "")
-						apiVersionName := strings.ReplaceAll(f.Name(), "-aws.json", "")
-						apiVersionName = strings.ReplaceAll(apiVersionName, "-azure.json", "")
+						apiVersionName := getGeneralVersionName(f.Name())
+						if versionName, ok := deploymentVersions[apiDeploymentName]; ok {
+							apiVersionName = versionName
+						}
 
 						// Create Deployment
 						deploymentFile, deployErr := os.Open(baseDir + "/" + e.Name() + "/" + f.Name())
@@ -456,7 +545,7 @@ func apiHubImport(flags *ApigeeFlags) error {
 							// update if it already exists, maybe we have a new version deployment...
 							if resp.StatusCode == 409 {
 								requestBody = bytes.NewBuffer(bodyBytes)
-								versionUrl = "https://apihub.googleapis.com/v1/projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + e.Name() + "/versions/" + k + "?updateMask=deployments"
+								versionUrl = "https://apihub.googleapis.com/v1/projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + e.Name() + "/versions/" + k + "?updateMask=deployments,description"
 								r, _ := http.NewRequest(http.MethodPatch, versionUrl, requestBody)
 								r.Header.Add("Content-Type", "application/json")
 								r.Header.Add("Authorization", "Bearer "+flags.Token)
@@ -477,32 +566,42 @@ func apiHubImport(flags *ApigeeFlags) error {
 					defer versionFile.Close()
 
 					for _, d := range v {
-						// Create API Version Spec
-						versionSpecFile, err := os.Open(baseDir + "/" + e.Name() + "/" + d + "-oas.json")
-						if err == nil {
-							var apiVersionSpec HubApiVersionSpec
-							byteValue, _ := io.ReadAll(versionSpecFile)
-							json.Unmarshal(byteValue, &apiVersionSpec)
-							requestBody := bytes.NewBuffer(byteValue)
+						specFiles := map[string]bool{}
+						for _, format := range generalSpecFormats {
+							// formats of the same spec type share one spec file
+							if specFiles[getApiHubSpecFile(d, format)] {
+								continue
+							}
+							specFiles[getApiHubSpecFile(d, format)] = true
 
-							versionUrl := "https://apihub.googleapis.com/v1/projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + e.Name() + "/versions/" + k + "/specs?specId=" + d
-							r, _ := http.NewRequest(http.MethodPost, versionUrl, requestBody)
-							r.Header.Add("Content-Type", "application/json")
-							r.Header.Add("Authorization", "Bearer "+flags.Token)
-							client := &http.Client{}
-							fmt.Println("Creating API version spec " + e.Name() + "...")
-							resp, _ := client.Do(r)
+							// Create API Version Spec
+							specId := getApiHubSpecId(d, format)
+							versionSpecFile, err := os.Open(baseDir + "/" + e.Name() + "/" + getApiHubSpecFile(d, format))
+							if err == nil {
+								var apiVersionSpec HubApiVersionSpec
+								byteValue, _ := io.ReadAll(versionSpecFile)
+								json.Unmarshal(byteValue, &apiVersionSpec)
+								requestBody := bytes.NewBuffer(byteValue)
 
-							if resp.StatusCode != 200 {
-								fmt.Println("  >> Error deploying version spec " + e.Name() + ": " + resp.Status)
-								defer resp.Body.Close()
-								//Read the response body
-								// respBody, _ := io.ReadAll(resp.Body)
-								// sb := string(respBody)
-								// fmt.Println(sb)
+								versionUrl := "https://apihub.googleapis.com/v1/projects/" + flags.Project + "/locations/" + flags.Region + "/apis/" + e.Name() + "/versions/" + k + "/specs?specId=" + specId
+								r, _ := http.NewRequest(http.MethodPost, versionUrl, requestBody)
+								r.Header.Add("Content-Type", "application/json")
+								r.Header.Add("Authorization", "Bearer "+flags.Token)
+								client := &http.Client{}
+								fmt.Println("Creating API version spec " + e.Name() + "...")
+								resp, _ := client.Do(r)
+
+								if resp.StatusCode != 200 {
+									fmt.Println("  >> Error deploying version spec " + e.Name() + ": " + resp.Status)
+									defer resp.Body.Close()
+									//Read the response body
+									// respBody, _ := io.ReadAll(resp.Body)
+									// sb := string(respBody)
+									// fmt.Println(sb)
+								}
 							}
+							defer versionSpecFile.Close()
 						}
-						defer versionSpecFile.Close()
 					}
 				}
 			}
@@ -512,6 +611,47 @@ func apiHubImport(flags *ApigeeFlags) error {
 	return nil
 }
 
+// getApiHubSpecId returns the spec id for a deployment spec, OpenAPI specs keep the deployment name.
+func getApiHubSpecId(deploymentName string, format GeneralSpecFormat) string {
+	if format.SpecType == "openapi" {
+		return deploymentName
+	}
+
+	return deploymentName + "-" + format.SpecType
+}
+
+func getApiHubSpecFile(deploymentName string, format GeneralSpecFormat) string {
+	if format.SpecType == "openapi" {
+		return deploymentName + "-oas.json"
+	}
+
+	return deploymentName + "-" + format.SpecType + ".json"
+}
+
+// getApiHubLocalDeploymentVersions maps onramped deployment names to the version that lists them.
+func getApiHubLocalDeploymentVersions(apiDir string) map[string]string {
+	result := make(map[string]string)
+
+	fileEntries, _ := os.ReadDir(apiDir)
+	for _, f := range fileEntries {
+		byteValue, err := os.ReadFile(apiDir + "/" + f.Name())
+		if err == nil {
+			var apiVersion HubApiVersion
+			json.Unmarshal(byteValue, &apiVersion)
+
+			if strings.Contains(apiVersion.Name, "/versions/") && !strings.Contains(apiVersion.Name, "/specs/") {
+				s := strings.Split(apiVersion.Name, "/")
+				for _, d := range apiVersion.Deployments {
+					ds := strings.Split(d, "/")
+					result[ds[len(ds)-1]] = s[len(s)-1]
+				}
+			}
+		}
+	}
+
+	return result
+}
+
 func apiHubExport(flags *ApigeeFlags) error {
 	baseDir := "src/main/apihub/apiproxies"
 
@@ -589,6 +729,8 @@ func apiHubExport(flags *ApigeeFlags) error {
 
 		apiVersionName := strings.ReplaceAll(deploymentName, "-aws", "")
 		apiVersionName = strings.ReplaceAll(apiVersionName, "-azure", "")
+		apiVersionName = strings.ReplaceAll(apiVersionName, "-apicenter", "")
+		apiVersionName = strings.ReplaceAll(apiVersionName, "-apigee", "")
 
 		var re = regexp.MustCompile(`(-v\d+)$`)
 		apiName := re.ReplaceAllString(apiVersionName, "")
@@ -3,17 +3,30 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	accounttypes "github.com/aws/aws-sdk-go-v2/service/account/types"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	apigatewaytypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
 	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/appsync"
+	appsynctypes "github.com/aws/aws-sdk-go-v2/service/appsync/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/tidwall/gjson"
 )
 
 type AwsApis struct {
@@ -53,11 +66,52 @@ type AwsFlags struct {
 	Region       string `name:"region" description:"The AWS region of the API Gateway."`
 	ApiName      string `name:"api" description:"A specific Azure API Management API."`
 	OnlyNew      bool   `name:"onlyNew" description:"If only newly discovered APIs should be processed."`
+	Regions      string `name:"regions" description:"A comma-separated list of AWS regions to discover APIs in, or 'all' for all enabled regions."`
+	RoleArns     string `name:"roles" description:"A comma-separated list of IAM role ARNs to assume, one for each AWS account."`
+	Stage        string `name:"stage" description:"The stage imported APIs are deployed to, $default if not set."`
+}
+
+// AwsImportLedger records the APIs created by imports, so that later imports update them.
+type AwsImportLedger struct {
+	Apis map[string]AwsImportedApi `json:"apis"`
+}
+
+type AwsImportedApi struct {
+	ApiId       string `json:"apiId"`
+	Region      string `json:"region"`
+	Stage       string `json:"stage"`
+	ApiEndpoint string `json:"apiEndpoint"`
+}
+
+type AwsUsagePlan struct {
+	apigatewaytypes.UsagePlan
+	Keys     []apigatewaytypes.UsagePlanKey `json:"keys"`
+	ApiNames map[string]string              `json:"apiNames"`
+}
+
+type AwsApiSecurity struct {
+	Authorizers []types.Authorizer `json:"authorizers"`
+	Routes      []types.Route      `json:"routes"`
+}
+
+// files stored next to an exported API that are not API definitions
+var awsSupportFileSuffixes = []string{"-oas.json", "-oas-definition.json", "-security.json", "-integrations.json"}
+
+type AwsTarget struct {
+	AccountId string
+	Region    string
+	Config    aws.Config
 }
 
 func awsCleanLocal(flags *AwsFlags) error {
 	var baseDir = "src/main/aws"
-	os.RemoveAll(baseDir)
+	entries, _ := os.ReadDir(baseDir)
+	for _, e := range entries {
+		// keep the import ledger, so imported APIs are still updated instead of duplicated
+		if e.Name() != "onramp" {
+			os.RemoveAll(baseDir + "/" + e.Name())
+		}
+	}
 	return nil
 }
 
@@ -74,6 +128,30 @@ func awsStatus(flags *AwsFlags) PlatformStatus {
 		os.Setenv("AWS_SECRET_ACCESS_KEY", flags.AccessSecret)
 	}
 
+	if flags.Regions != "" || flags.RoleArns != "" {
+		// discovery mode, report counts per account and region
+		total := 0
+		counts := []string{}
+		for _, target := range getAwsTargets(flags) {
+			client := apigatewayv2.NewFromConfig(target.Config)
+			apis, _ := client.GetApis(context.TODO(), &apigatewayv2.GetApisInput{})
+
+			if apis != nil {
+				status.Connected = true
+				total += len(apis.Items)
+				counts = append(counts, target.AccountId+"/"+target.Region+": "+strconv.Itoa(len(apis.Items)))
+			}
+		}
+
+		if status.Connected {
+			status.Message = "Connected to Aws, " + strconv.Itoa(total) + " API(s) found (" + strings.Join(counts, ", ") + ")."
+		} else {
+			status.Message = "Could not connect to any AWS account or region."
+		}
+
+		return status
+	}
+
 	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(flags.Region))
 	if err != nil {
 		log.Fatal(err)
@@ -114,79 +192,680 @@ func awsExport(flags *AwsFlags) ([]string, error) {
 	}
 
 	client := apigatewayv2.NewFromConfig(cfg)
+
+	if client == nil {
+		fmt.Println("AWS client could not be created, cannot export APIs.")
+		return nil, nil
+	}
+
+	fmt.Println("Exporting AWS APIs for region " + flags.Region + "...")
+	apiNames := awsExportApis(client, flags, flags.Region, baseDir)
+	awsExportUsagePlans(cfg, "src/main/aws")
+
+	return apiNames, nil
+}
+
+func awsDiscoverMin(flags *AwsFlags) error {
+	awsDiscover(flags)
+	return nil
+}
+
+func awsDiscover(flags *AwsFlags) ([]string, error) {
+	if flags.AccessKey != "" {
+		os.Setenv("AWS_ACCESS_KEY_ID", flags.AccessKey)
+	}
+	if flags.AccessSecret != "" {
+		os.Setenv("AWS_SECRET_ACCESS_KEY", flags.AccessSecret)
+	}
+
 	apiNames := []string{}
+	targets := getAwsTargets(flags)
+	if len(targets) == 0 {
+		fmt.Println("No AWS accounts or regions could be resolved, cannot discover APIs.")
+		return apiNames, nil
+	}
 
-	if client != nil {
-		fmt.Println("Exporting AWS APIs for region " + flags.Region + "...")
+	for _, target := range targets {
+		// each account and region is exported to its own namespace in the local store
+		baseDir := "src/main/aws/accounts/" + target.AccountId + "/" + target.Region + "/apiproxies"
+		client := apigatewayv2.NewFromConfig(target.Config)
 
-		apis, _ := client.GetApis(context.TODO(), &apigatewayv2.GetApisInput{})
-		if apis != nil {
-			if len(apis.Items) > 0 {
-				for _, api := range apis.Items {
-					if flags.ApiName == "" || flags.ApiName == *api.Name {
-						fmt.Println("Exporting " + *api.Name + "...")
-						outputType := "JSON"
-						specType := "OAS30"
-						apiExport, exportErr := client.ExportApi(context.TODO(), &apigatewayv2.ExportApiInput{
-							ApiId:         api.ApiId,
-							OutputType:    &outputType,
-							Specification: &specType,
-						})
-
-						if exportErr != nil {
-							fmt.Println(exportErr)
-						}
+		fmt.Println("Exporting AWS APIs for account " + target.AccountId + " and region " + target.Region + "...")
+		apiNames = append(apiNames, awsExportApis(client, flags, target.Region, baseDir)...)
+		awsExportUsagePlans(target.Config, filepath.Dir(baseDir))
+		apiNames = append(apiNames, awsExportGraphqlApis(target.Config, flags, filepath.Dir(baseDir)+"/graphql")...)
+	}
+
+	return apiNames, nil
+}
 
-						bytes, _ := json.MarshalIndent(api, "", "  ")
-						newName := strings.ReplaceAll(strings.ToLower(*api.Name), " ", "-")
+func awsExportApis(client *apigatewayv2.Client, flags *AwsFlags, region string, baseDir string) []string {
+	apiNames := []string{}
 
-						var re = regexp.MustCompile(`(-v\d+)$`)
-						newName2 := re.ReplaceAllString(newName, "")
+	apis, _ := client.GetApis(context.TODO(), &apigatewayv2.GetApisInput{})
+	if apis != nil {
+		if len(apis.Items) > 0 {
+			for _, api := range apis.Items {
+				if flags.ApiName == "" || flags.ApiName == *api.Name {
+					fmt.Println("Exporting " + *api.Name + "...")
+					outputType := "JSON"
+					specType := "OAS30"
+					apiExport, exportErr := client.ExportApi(context.TODO(), &apigatewayv2.ExportApiInput{
+						ApiId:         api.ApiId,
+						OutputType:    &outputType,
+						Specification: &specType,
+					})
 
-						_, fileExistsErr := os.Open(baseDir + "/" + newName2 + "/" + newName + ".json")
+					if exportErr != nil {
+						fmt.Println(exportErr)
+					}
 
-						if (flags.OnlyNew && fileExistsErr != nil) || !flags.OnlyNew {
-							os.MkdirAll(baseDir+"/"+newName2, 0755)
-							writeError := os.WriteFile(baseDir+"/"+newName2+"/"+newName+".json", bytes, 0644)
-							if writeError != nil {
-								fmt.Println(writeError)
-							}
-							if apiExport != nil && apiExport.Body != nil {
-								os.WriteFile(baseDir+"/"+newName2+"/"+newName+"-oas.json", apiExport.Body, 0644)
-							}
+					security := getAwsApiSecurity(client, *api.ApiId)
+					if apiExport != nil && apiExport.Body != nil {
+						apiExport.Body = addAwsSecurityToSpec(apiExport.Body, security)
+					}
+
+					bytes, _ := json.MarshalIndent(api, "", "  ")
+					newName := strings.ReplaceAll(strings.ToLower(*api.Name), " ", "-")
 
-							apiNames = append(apiNames, newName)
+					var re = regexp.MustCompile(`(-v\d+)$`)
+					newName2 := re.ReplaceAllString(newName, "")
+
+					_, fileExistsErr := os.Open(baseDir + "/" + newName2 + "/" + newName + ".json")
+
+					if (flags.OnlyNew && fileExistsErr != nil) || !flags.OnlyNew {
+						os.MkdirAll(baseDir+"/"+newName2, 0755)
+						writeError := os.WriteFile(baseDir+"/"+newName2+"/"+newName+".json", bytes, 0644)
+						if writeError != nil {
+							fmt.Println(writeError)
+						}
+						if apiExport != nil && apiExport.Body != nil {
+							os.WriteFile(baseDir+"/"+newName2+"/"+newName+"-oas.json", apiExport.Body, 0644)
 						}
+						securityBytes, _ := json.MarshalIndent(security, "", "  ")
+						os.WriteFile(baseDir+"/"+newName2+"/"+newName+"-security.json", securityBytes, 0644)
+						integrationBytes, _ := json.MarshalIndent(getAwsApiIntegrations(client, *api.ApiId), "", "  ")
+						os.WriteFile(baseDir+"/"+newName2+"/"+newName+"-integrations.json", integrationBytes, 0644)
+
+						apiNames = append(apiNames, newName)
 					}
 				}
-			} else {
-				fmt.Println("No AWS APIs found in region " + flags.Region + ", cannot export APIs.")
-				return nil, nil
 			}
 		} else {
-			fmt.Println("No valid APIs found in region " + flags.Region + ", cannot export APIs.")
-			return nil, nil
+			fmt.Println("No AWS APIs found in region " + region + ", cannot export APIs.")
 		}
 	} else {
-		fmt.Println("AWS client could not be created, cannot export APIs.")
-		return nil, nil
+		fmt.Println("No valid APIs found in region " + region + ", cannot export APIs.")
+	}
+
+	return apiNames
+}
+
+func getAwsApiSecurity(client *apigatewayv2.Client, apiId string) AwsApiSecurity {
+	security := AwsApiSecurity{Authorizers: []types.Authorizer{}, Routes: []types.Route{}}
+
+	var nextToken *string
+	for {
+		authorizers, err := client.GetAuthorizers(context.TODO(), &apigatewayv2.GetAuthorizersInput{ApiId: &apiId, NextToken: nextToken})
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		security.Authorizers = append(security.Authorizers, authorizers.Items...)
+		nextToken = authorizers.NextToken
+		if nextToken == nil {
+			break
+		}
+	}
+
+	nextToken = nil
+	for {
+		routes, err := client.GetRoutes(context.TODO(), &apigatewayv2.GetRoutesInput{ApiId: &apiId, NextToken: nextToken})
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		security.Routes = append(security.Routes, routes.Items...)
+		nextToken = routes.NextToken
+		if nextToken == nil {
+			break
+		}
 	}
 
+	return security
+}
+
+func getAwsApiIntegrations(client *apigatewayv2.Client, apiId string) []types.Integration {
+	result := []types.Integration{}
+
+	var nextToken *string
+	for {
+		integrations, err := client.GetIntegrations(context.TODO(), &apigatewayv2.GetIntegrationsInput{ApiId: &apiId, NextToken: nextToken})
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		result = append(result, integrations.Items...)
+		nextToken = integrations.NextToken
+		if nextToken == nil {
+			break
+		}
+	}
+
+	return result
+}
+
+// getGeneralBackends maps AWS integrations to general backend targets, with the routes that call them.
+func getGeneralBackends(integrations []types.Integration, routes []types.Route) []GeneralBackend {
+	backends := []GeneralBackend{}
+
+	for _, integration := range integrations {
+		if integration.IntegrationId == nil {
+			continue
+		}
+
+		backend := GeneralBackend{Name: *integration.IntegrationId, Routes: []string{}}
+		if integration.IntegrationUri != nil {
+			backend.Url = *integration.IntegrationUri
+		}
+		if integration.IntegrationMethod != nil {
+			backend.Method = *integration.IntegrationMethod
+		}
+
+		switch {
+		case integration.ConnectionType == types.ConnectionTypeVpcLink:
+			backend.Type = "vpc-link"
+			if integration.ConnectionId != nil {
+				backend.ConnectionId = *integration.ConnectionId
+			}
+		case integration.IntegrationType == types.IntegrationTypeMock:
+			backend.Type = "mock"
+		case integration.IntegrationType == types.IntegrationTypeHttp || integration.IntegrationType == types.IntegrationTypeHttpProxy:
+			backend.Type = "http"
+		case integration.IntegrationSubtype != nil:
+			backend.Type = "aws-service"
+			backend.Url = *integration.IntegrationSubtype
+		case strings.Contains(backend.Url, ":lambda:"):
+			backend.Type = "lambda"
+			// REST style uris wrap the function arn in an invocation path
+			if i := strings.Index(backend.Url, "arn:aws:lambda:"); i >= 0 {
+				backend.Url = strings.TrimSuffix(backend.Url[i:], "/invocations")
+			}
+		default:
+			backend.Type = "aws-service"
+		}
+
+		for _, route := range routes {
+			if route.Target != nil && route.RouteKey != nil && *route.Target == "integrations/"+*integration.IntegrationId {
+				backend.Routes = append(backend.Routes, *route.RouteKey)
+			}
+		}
+
+		backends = append(backends, backend)
+	}
+
+	return backends
+}
+
+// addAwsSecurityToSpec adds securitySchemes for the API authorizers and a security requirement to each authorized route.
+func addAwsSecurityToSpec(spec []byte, security AwsApiSecurity) []byte {
+	var oas map[string]any
+	if json.Unmarshal(spec, &oas) != nil {
+		return spec
+	}
+
+	components, _ := oas["components"].(map[string]any)
+	if components == nil {
+		components = make(map[string]any)
+		oas["components"] = components
+	}
+	schemes, _ := components["securitySchemes"].(map[string]any)
+	if schemes == nil {
+		schemes = make(map[string]any)
+	}
+
+	paths, _ := oas["paths"].(map[string]any)
+	for _, route := range security.Routes {
+		schemeName := ""
+		if route.AuthorizationType == types.AuthorizationTypeAwsIam {
+			schemeName = "sigv4"
+			if _, ok := schemes[schemeName]; !ok {
+				schemes[schemeName] = map[string]any{"type": "apiKey", "name": "Authorization", "in": "header", "x-amazon-apigateway-authtype": "awsSigv4"}
+			}
+		} else if route.AuthorizerId != nil {
+			for _, authorizer := range security.Authorizers {
+				if authorizer.AuthorizerId != nil && *authorizer.AuthorizerId == *route.AuthorizerId {
+					schemeName = *authorizer.Name
+					if _, ok := schemes[schemeName]; !ok {
+						schemes[schemeName] = getAwsSecurityScheme(authorizer)
+					}
+				}
+			}
+		}
+
+		if schemeName == "" || route.RouteKey == nil {
+			continue
+		}
+
+		scopes := route.AuthorizationScopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+		requirement := []any{map[string]any{schemeName: scopes}}
+
+		if *route.RouteKey == "$default" {
+			if _, ok := oas["security"]; !ok {
+				oas["security"] = requirement
+			}
+			continue
+		}
+
+		routeKey := strings.SplitN(*route.RouteKey, " ", 2)
+		if len(routeKey) == 2 && paths != nil {
+			if pathItem, ok := paths[routeKey[1]].(map[string]any); ok {
+				for method, operation := range pathItem {
+					if strings.EqualFold(method, routeKey[0]) || (routeKey[0] == "ANY" && method != "parameters") {
+						if op, ok := operation.(map[string]any); ok {
+							if _, ok := op["security"]; !ok {
+								op["security"] = requirement
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if len(schemes) > 0 {
+		components["securitySchemes"] = schemes
+	}
+
+	result, err := json.MarshalIndent(oas, "", "  ")
+	if err != nil {
+		return spec
+	}
+
+	return result
+}
+
+func getAwsSecurityScheme(authorizer types.Authorizer) map[string]any {
+	if authorizer.AuthorizerType == types.AuthorizerTypeJwt && authorizer.JwtConfiguration != nil && authorizer.JwtConfiguration.Issuer != nil {
+		return map[string]any{"type": "openIdConnect", "openIdConnectUrl": strings.TrimSuffix(*authorizer.JwtConfiguration.Issuer, "/") + "/.well-known/openid-configuration"}
+	}
+
+	// lambda authorizers read their identity from a header or query parameter
+	scheme := map[string]any{"type": "apiKey", "name": "Authorization", "in": "header", "x-amazon-apigateway-authtype": "custom"}
+	if len(authorizer.IdentitySource) > 0 {
+		source := authorizer.IdentitySource[0]
+		if strings.HasPrefix(source, "$request.header.") {
+			scheme["name"] = strings.TrimPrefix(source, "$request.header.")
+		} else if strings.HasPrefix(source, "$request.querystring.") {
+			scheme["name"] = strings.TrimPrefix(source, "$request.querystring.")
+			scheme["in"] = "query"
+		}
+	}
+
+	return scheme
+}
+
+func getGeneralSecuritySchemes(security AwsApiSecurity) []GeneralSecurityScheme {
+	schemes := []GeneralSecurityScheme{}
+
+	for _, authorizer := range security.Authorizers {
+		used := false
+		for _, route := range security.Routes {
+			if route.AuthorizerId != nil && authorizer.AuthorizerId != nil && *route.AuthorizerId == *authorizer.AuthorizerId {
+				used = true
+			}
+		}
+
+		if used {
+			scheme := GeneralSecurityScheme{Name: *authorizer.Name, Type: "lambda"}
+			if authorizer.AuthorizerType == types.AuthorizerTypeJwt {
+				scheme.Type = "jwt"
+				if authorizer.JwtConfiguration != nil {
+					if authorizer.JwtConfiguration.Issuer != nil {
+						scheme.Issuer = *authorizer.JwtConfiguration.Issuer
+					}
+					scheme.Audiences = authorizer.JwtConfiguration.Audience
+				}
+			} else if len(authorizer.IdentitySource) > 0 {
+				scheme.IdentitySource = authorizer.IdentitySource[0]
+			}
+			schemes = append(schemes, scheme)
+		}
+	}
+
+	for _, route := range security.Routes {
+		if route.AuthorizationType == types.AuthorizationTypeAwsIam {
+			schemes = append(schemes, GeneralSecurityScheme{Name: "sigv4", Type: "iam"})
+			break
+		}
+	}
+
+	return schemes
+}
+
+func isAwsApiFile(fileName string) bool {
+	for _, suffix := range awsSupportFileSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return false
+		}
+	}
+
+	return strings.HasSuffix(fileName, ".json")
+}
+
+func awsExportUsagePlans(cfg aws.Config, baseDir string) {
+	client := apigateway.NewFromConfig(cfg)
+
+	// usage plans only reference REST API ids, so keep the names to link them to general APIs later
+	apiNames := make(map[string]string)
+	restApis := apigateway.NewGetRestApisPaginator(client, &apigateway.GetRestApisInput{})
+	for restApis.HasMorePages() {
+		page, err := restApis.NextPage(context.TODO())
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		for _, api := range page.Items {
+			apiNames[*api.Id] = *api.Name
+		}
+	}
+
+	plans := apigateway.NewGetUsagePlansPaginator(client, &apigateway.GetUsagePlansInput{})
+	for plans.HasMorePages() {
+		page, err := plans.NextPage(context.TODO())
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+
+		for _, plan := range page.Items {
+			fmt.Println("Exporting usage plan " + *plan.Name + "...")
+			usagePlan := AwsUsagePlan{UsagePlan: plan, Keys: []apigatewaytypes.UsagePlanKey{}, ApiNames: make(map[string]string)}
+
+			keys := apigateway.NewGetUsagePlanKeysPaginator(client, &apigateway.GetUsagePlanKeysInput{UsagePlanId: plan.Id})
+			for keys.HasMorePages() {
+				keyPage, err := keys.NextPage(context.TODO())
+				if err != nil {
+					fmt.Println(err)
+					break
+				}
+				for _, key := range keyPage.Items {
+					// never store the key values locally
+					key.Value = nil
+					usagePlan.Keys = append(usagePlan.Keys, key)
+				}
+			}
+
+			for _, stage := range plan.ApiStages {
+				if name, ok := apiNames[*stage.ApiId]; ok {
+					usagePlan.ApiNames[*stage.ApiId] = name
+				}
+			}
+
+			bytes, _ := json.MarshalIndent(usagePlan, "", "  ")
+			os.MkdirAll(baseDir+"/products", 0755)
+			os.WriteFile(baseDir+"/products/"+strings.ReplaceAll(strings.ToLower(*plan.Name), " ", "-")+".json", bytes, 0644)
+		}
+	}
+}
+
+func awsGraphqlExportMin(flags *AwsFlags) error {
+	awsGraphqlExport(flags)
+	return nil
+}
+
+func awsGraphqlExport(flags *AwsFlags) ([]string, error) {
+	if flags.Region == "" {
+		flags.Region = os.Getenv("AWS_REGION")
+		if flags.Region == "" {
+			fmt.Println("No region given, cannot export AWS AppSync APIs.")
+			return nil, nil
+		}
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(flags.Region))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Exporting AWS AppSync APIs for region " + flags.Region + "...")
+	apiNames := awsExportGraphqlApis(cfg, flags, "src/main/aws/graphql")
+
 	return apiNames, nil
 }
 
+func awsExportGraphqlApis(cfg aws.Config, flags *AwsFlags, baseDir string) []string {
+	client := appsync.NewFromConfig(cfg)
+	apiNames := []string{}
+
+	var nextToken *string
+	for {
+		apis, err := client.ListGraphqlApis(context.TODO(), &appsync.ListGraphqlApisInput{NextToken: nextToken})
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+
+		for _, api := range apis.GraphqlApis {
+			if flags.ApiName == "" || flags.ApiName == *api.Name {
+				fmt.Println("Exporting " + *api.Name + "...")
+				newName := strings.ReplaceAll(strings.ToLower(*api.Name), " ", "-")
+
+				var re = regexp.MustCompile(`(-v\d+)$`)
+				newName2 := re.ReplaceAllString(newName, "")
+
+				_, fileExistsErr := os.Stat(baseDir + "/" + newName2 + "/" + newName + ".json")
+
+				if (flags.OnlyNew && fileExistsErr != nil) || !flags.OnlyNew {
+					bytes, _ := json.MarshalIndent(api, "", "  ")
+					os.MkdirAll(baseDir+"/"+newName2, 0755)
+					os.WriteFile(baseDir+"/"+newName2+"/"+newName+".json", bytes, 0644)
+
+					schema, err := client.GetIntrospectionSchema(context.TODO(), &appsync.GetIntrospectionSchemaInput{
+						ApiId:  api.ApiId,
+						Format: appsynctypes.OutputTypeSdl,
+					})
+					if err != nil {
+						fmt.Println(err)
+					} else if schema.Schema != nil {
+						os.WriteFile(baseDir+"/"+newName2+"/"+newName+"-schema.graphql", schema.Schema, 0644)
+					}
+
+					apiNames = append(apiNames, newName)
+				}
+			}
+		}
+
+		nextToken = apis.NextToken
+		if nextToken == nil {
+			break
+		}
+	}
+
+	return apiNames
+}
+
+func getAwsGraphqlSecuritySchemes(api appsynctypes.GraphqlApi, region string) []GeneralSecurityScheme {
+	authenticationTypes := []appsynctypes.AuthenticationType{api.AuthenticationType}
+	for _, provider := range api.AdditionalAuthenticationProviders {
+		authenticationTypes = append(authenticationTypes, provider.AuthenticationType)
+	}
+
+	schemes := []GeneralSecurityScheme{}
+	for _, authenticationType := range authenticationTypes {
+		scheme := GeneralSecurityScheme{Name: strings.ToLower(string(authenticationType))}
+		switch authenticationType {
+		case appsynctypes.AuthenticationTypeApiKey:
+			scheme.Type = "apiKey"
+			scheme.IdentitySource = "$request.header.x-api-key"
+		case appsynctypes.AuthenticationTypeAwsIam:
+			scheme.Type = "iam"
+		case appsynctypes.AuthenticationTypeAwsLambda:
+			scheme.Type = "lambda"
+			scheme.IdentitySource = "$request.header.Authorization"
+		case appsynctypes.AuthenticationTypeOpenidConnect:
+			scheme.Type = "jwt"
+			if api.OpenIDConnectConfig != nil && api.OpenIDConnectConfig.Issuer != nil {
+				scheme.Issuer = *api.OpenIDConnectConfig.Issuer
+			}
+		case appsynctypes.AuthenticationTypeAmazonCognitoUserPools:
+			scheme.Type = "jwt"
+			if api.UserPoolConfig != nil && api.UserPoolConfig.UserPoolId != nil {
+				poolRegion := region
+				if api.UserPoolConfig.AwsRegion != nil {
+					poolRegion = *api.UserPoolConfig.AwsRegion
+				}
+				scheme.Issuer = "https://cognito-idp." + poolRegion + ".amazonaws.com/" + *api.UserPoolConfig.UserPoolId
+			}
+		default:
+			continue
+		}
+		schemes = append(schemes, scheme)
+	}
+
+	return schemes
+}
+
+// getAwsTargets resolves every account (one per role, or the default credentials) and region to export.
+func getAwsTargets(flags *AwsFlags) []AwsTarget {
+	targets := []AwsTarget{}
+
+	baseRegion := flags.Region
+	if baseRegion == "" {
+		baseRegion = os.Getenv("AWS_REGION")
+	}
+	if baseRegion == "" {
+		baseRegion = "us-east-1"
+	}
+
+	roleArns := splitAwsList(flags.RoleArns)
+	if len(roleArns) == 0 {
+		// use the default credentials
+		roleArns = []string{""}
+	}
+
+	for _, roleArn := range roleArns {
+		cfg, err := getAwsConfig(baseRegion, roleArn)
+		if err != nil {
+			fmt.Println("Could not load AWS config for role " + roleArn + ": " + err.Error())
+			continue
+		}
+
+		accountId := getAwsAccountId(cfg)
+		if accountId == "" {
+			fmt.Println("Could not get AWS account for role " + roleArn + ", skipping.")
+			continue
+		}
+
+		regions := []string{baseRegion}
+		if flags.Regions == "all" {
+			regions = getAwsEnabledRegions(cfg)
+		} else if flags.Regions != "" {
+			regions = splitAwsList(flags.Regions)
+		}
+
+		for _, region := range regions {
+			regionCfg := cfg.Copy()
+			regionCfg.Region = region
+			targets = append(targets, AwsTarget{AccountId: accountId, Region: region, Config: regionCfg})
+		}
+	}
+
+	return targets
+}
+
+func getAwsConfig(region string, roleArn string) (aws.Config, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	if err == nil && roleArn != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = "oasync"
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return cfg, err
+}
+
+func getAwsAccountId(cfg aws.Config) string {
+	var result string
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		fmt.Println(err)
+	} else if identity.Account != nil {
+		result = *identity.Account
+	}
+
+	return result
+}
+
+func getAwsEnabledRegions(cfg aws.Config) []string {
+	regions := []string{}
+	client := account.NewFromConfig(cfg)
+
+	paginator := account.NewListRegionsPaginator(client, &account.ListRegionsInput{
+		RegionOptStatusContains: []accounttypes.RegionOptStatus{accounttypes.RegionOptStatusEnabled, accounttypes.RegionOptStatusEnabledByDefault},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+
+		for _, region := range page.Regions {
+			regions = append(regions, *region.RegionName)
+		}
+	}
+
+	return regions
+}
+
+func splitAwsList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) != "" {
+			result = append(result, strings.TrimSpace(item))
+		}
+	}
+
+	return result
+}
+
 func awsOfframp(flags *AwsFlags) error {
 
-	awsBaseDir := "src/main/aws/apiproxies"
-	baseDir := "src/main/general/apiproxies"
+	awsBaseDir := "src/main/aws"
+
+	// discovered APIs are stored per account and region
+	stores, _ := filepath.Glob("src/main/aws/accounts/*/*")
 
-	entries, err := os.ReadDir(awsBaseDir)
+	_, err := os.ReadDir(awsBaseDir)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("Offramping AWS API Gateway APIs to general...")
+	fmt.Println("Offramping AWS API Gateway and AppSync APIs to general...")
+
+	awsOfframpApis(flags, awsBaseDir+"/apiproxies", "", flags.Region)
+	awsOfframpUsagePlans(awsBaseDir+"/products", "", flags.Region)
+	awsOfframpGraphqlApis(flags, awsBaseDir+"/graphql", "", flags.Region)
+
+	for _, store := range stores {
+		s := strings.Split(filepath.ToSlash(store), "/")
+		awsOfframpApis(flags, store+"/apiproxies", s[len(s)-2], s[len(s)-1])
+		awsOfframpUsagePlans(store+"/products", s[len(s)-2], s[len(s)-1])
+		awsOfframpGraphqlApis(flags, store+"/graphql", s[len(s)-2], s[len(s)-1])
+	}
+
+	return nil
+}
+
+func awsOfframpApis(flags *AwsFlags, awsBaseDir string, accountId string, region string) {
+	baseDir := "src/main/general/apiproxies"
 
+	entries, _ := os.ReadDir(awsBaseDir)
 	for _, e := range entries {
 		if flags.ApiName == "" || flags.ApiName == e.Name() {
 			fmt.Println(e.Name())
@@ -194,7 +873,7 @@ func awsOfframp(flags *AwsFlags) error {
 			// read all files
 			fileEntries, _ := os.ReadDir(awsBaseDir + "/" + e.Name())
 			for _, f := range fileEntries {
-				if !strings.HasSuffix(f.Name(), "-oas.json") && !strings.HasSuffix(f.Name(), "-oas-definition.json") {
+				if isAwsApiFile(f.Name()) {
 					var awsApi types.Api
 					apiFile, err := os.Open(awsBaseDir + "/" + e.Name() + "/" + f.Name())
 					if err != nil {
@@ -209,13 +888,34 @@ func awsOfframp(flags *AwsFlags) error {
 						var generalApi GeneralApi
 						baseName := strings.ReplaceAll(strings.ToLower(*awsApi.Name), " ", "-")
 						generalApi.Name = baseName + "-aws"
+						if accountId != "" {
+							// keep deployments from different accounts and regions apart
+							generalApi.Name = baseName + "-" + accountId + "-" + region + "-aws"
+							generalApi.VersionName = baseName
+							generalApi.AccountId = accountId
+							generalApi.Region = region
+						}
 						generalApi.DisplayName = *awsApi.Name
 						generalApi.Description = *awsApi.Description
 						generalApi.Version = *awsApi.Version
 						generalApi.GatewayUrl = *awsApi.ApiEndpoint
 						generalApi.PlatformId = "aws-api-gateway"
 						generalApi.PlatformName = "AWS API Gateway"
-						generalApi.PlatformResourceUri = "https://" + flags.Region + ".console.aws.amazon.com/apigateway/main/apis?api=" + *awsApi.ApiId
+						generalApi.PlatformResourceUri = "https://" + region + ".console.aws.amazon.com/apigateway/main/apis?api=" + *awsApi.ApiId
+
+						var security AwsApiSecurity
+						securityBytes, err := os.ReadFile(awsBaseDir + "/" + e.Name() + "/" + baseName + "-security.json")
+						if err == nil {
+							json.Unmarshal(securityBytes, &security)
+							generalApi.SecuritySchemes = getGeneralSecuritySchemes(security)
+						}
+
+						var integrations []types.Integration
+						integrationBytes, err := os.ReadFile(awsBaseDir + "/" + e.Name() + "/" + baseName + "-integrations.json")
+						if err == nil {
+							json.Unmarshal(integrationBytes, &integrations)
+							generalApi.Backends = getGeneralBackends(integrations, security.Routes)
+						}
 
 						bytes, _ := json.MarshalIndent(generalApi, "", "  ")
 						//os.RemoveAll(baseDir + "/" + generalApi.Name)
@@ -236,6 +936,290 @@ func awsOfframp(flags *AwsFlags) error {
 			}
 		}
 	}
+}
+
+func awsOfframpUsagePlans(awsProductsDir string, accountId string, region string) {
+	entries, _ := os.ReadDir(awsProductsDir)
+	for _, e := range entries {
+		var usagePlan AwsUsagePlan
+		byteValue, err := os.ReadFile(awsProductsDir + "/" + e.Name())
+		if err == nil {
+			json.Unmarshal(byteValue, &usagePlan)
+		}
+
+		if usagePlan.Name != nil && *usagePlan.Name != "" {
+			fmt.Println(*usagePlan.Name)
+			var generalProduct GeneralProduct
+			baseName := strings.ReplaceAll(strings.ToLower(*usagePlan.Name), " ", "-")
+			generalProduct.Name = baseName + "-aws"
+			if accountId != "" {
+				generalProduct.Name = baseName + "-" + accountId + "-" + region + "-aws"
+			}
+			generalProduct.DisplayName = *usagePlan.Name
+			if usagePlan.Description != nil {
+				generalProduct.Description = *usagePlan.Description
+			}
+			generalProduct.Apis = []string{}
+			for _, stage := range usagePlan.ApiStages {
+				if name, ok := usagePlan.ApiNames[*stage.ApiId]; ok {
+					var re = regexp.MustCompile(`(-v\d+)$`)
+					apiName := re.ReplaceAllString(strings.ReplaceAll(strings.ToLower(name), " ", "-"), "")
+					if _, err := os.Stat("src/main/general/apiproxies/" + apiName); err != nil {
+						// usage plans only attach to REST APIs, which are not offramped
+						fmt.Println("  >> Not linking " + name + ", usage plans are only linked to general APIs and REST APIs are not offramped.")
+						continue
+					}
+					if !slices.Contains(generalProduct.Apis, apiName) {
+						generalProduct.Apis = append(generalProduct.Apis, apiName)
+					}
+				}
+			}
+			if usagePlan.Quota != nil {
+				generalProduct.QuotaLimit = int(usagePlan.Quota.Limit)
+				generalProduct.QuotaInterval = 1
+				generalProduct.QuotaTimeUnit = strings.ToLower(string(usagePlan.Quota.Period))
+				if usagePlan.Quota.Period == apigatewaytypes.QuotaPeriodTypeWeek {
+					generalProduct.QuotaInterval = 7
+					generalProduct.QuotaTimeUnit = "day"
+				}
+			}
+			if usagePlan.Throttle != nil {
+				generalProduct.RateLimit = usagePlan.Throttle.RateLimit
+				generalProduct.BurstLimit = int(usagePlan.Throttle.BurstLimit)
+			}
+			generalProduct.Subscriptions = len(usagePlan.Keys)
+			generalProduct.PlatformId = "aws-api-gateway"
+			generalProduct.PlatformName = "AWS API Gateway"
+			generalProduct.PlatformResourceUri = "https://" + region + ".console.aws.amazon.com/apigateway/main/usage-plans/" + *usagePlan.Id
+
+			writeGeneralProduct(generalProduct)
+		}
+	}
+}
+
+func awsOfframpGraphqlApis(flags *AwsFlags, awsGraphqlDir string, accountId string, region string) {
+	baseDir := "src/main/general/apiproxies"
+
+	entries, _ := os.ReadDir(awsGraphqlDir)
+	for _, e := range entries {
+		if flags.ApiName == "" || flags.ApiName == e.Name() {
+			fmt.Println(e.Name())
+
+			fileEntries, _ := os.ReadDir(awsGraphqlDir + "/" + e.Name())
+			for _, f := range fileEntries {
+				if strings.HasSuffix(f.Name(), ".json") {
+					var graphqlApi appsynctypes.GraphqlApi
+					byteValue, err := os.ReadFile(awsGraphqlDir + "/" + e.Name() + "/" + f.Name())
+					if err == nil {
+						json.Unmarshal(byteValue, &graphqlApi)
+					}
+
+					if graphqlApi.Name != nil && *graphqlApi.Name != "" {
+						var generalApi GeneralApi
+						baseName := strings.ReplaceAll(strings.ToLower(*graphqlApi.Name), " ", "-")
+						// AppSync APIs can share a name with API Gateway APIs, so their deployments get their own suffix
+						generalApi.Name = baseName + "-appsync-aws"
+						if accountId != "" {
+							generalApi.Name = baseName + "-" + accountId + "-" + region + "-appsync-aws"
+							generalApi.VersionName = baseName
+							generalApi.AccountId = accountId
+							generalApi.Region = region
+						}
+						generalApi.DisplayName = *graphqlApi.Name
+						generalApi.Protocol = "graphql"
+						generalApi.GatewayUrl = graphqlApi.Uris["GRAPHQL"]
+						if graphqlApi.OwnerContact != nil {
+							generalApi.OwnerEmail = *graphqlApi.OwnerContact
+						}
+						generalApi.PlatformId = "aws-appsync"
+						generalApi.PlatformName = "AWS AppSync"
+						generalApi.PlatformResourceUri = "https://" + region + ".console.aws.amazon.com/appsync/home?region=" + region + "#/" + *graphqlApi.ApiId + "/v1/home"
+						generalApi.SecuritySchemes = getAwsGraphqlSecuritySchemes(graphqlApi, region)
+
+						bytes, _ := json.MarshalIndent(generalApi, "", "  ")
+						os.MkdirAll(baseDir+"/"+e.Name(), 0755)
+
+						writeGeneralApi(e.Name(), generalApi)
+						os.WriteFile(baseDir+"/"+e.Name()+"/"+generalApi.Name+".json", bytes, 0644)
+
+						schemaBytes, err := os.ReadFile(awsGraphqlDir + "/" + e.Name() + "/" + baseName + "-schema.graphql")
+						if err == nil {
+							// we have a schema, copy it over
+							os.WriteFile(baseDir+"/"+e.Name()+"/"+generalApi.Name+"-schema.graphql", schemaBytes, 0644)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func awsImport(flags *AwsFlags) error {
+	generalBaseDir := "src/main/general/apiproxies"
+	ledgerFile := "src/main/aws/onramp/ledger.json"
+
+	if flags.Region == "" {
+		flags.Region = os.Getenv("AWS_REGION")
+		if flags.Region == "" {
+			fmt.Println("No region given, cannot import AWS APIs.")
+			return nil
+		}
+	}
+	if flags.AccessKey != "" {
+		os.Setenv("AWS_ACCESS_KEY_ID", flags.AccessKey)
+	}
+	if flags.AccessSecret != "" {
+		os.Setenv("AWS_SECRET_ACCESS_KEY", flags.AccessSecret)
+	}
+	stage := flags.Stage
+	if stage == "" {
+		stage = "$default"
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(flags.Region))
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := apigatewayv2.NewFromConfig(cfg)
+
+	entries, err := os.ReadDir(generalBaseDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ledger := AwsImportLedger{Apis: map[string]AwsImportedApi{}}
+	byteValue, err := os.ReadFile(ledgerFile)
+	if err == nil {
+		json.Unmarshal(byteValue, &ledger)
+		if ledger.Apis == nil {
+			ledger.Apis = map[string]AwsImportedApi{}
+		}
+	}
+
+	fmt.Println("Importing general APIs to AWS API Gateway in region " + flags.Region + "...")
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		// one HTTP API is imported per version, from the first deployment with a spec
+		importedVersions := make(map[string]bool)
+		fileEntries, _ := os.ReadDir(generalBaseDir + "/" + e.Name())
+		for _, f := range fileEntries {
+			if !isGeneralDeploymentFile(f.Name()) {
+				continue
+			}
+
+			var generalDeploymentApi GeneralApi
+			byteValue, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + f.Name())
+			if err == nil {
+				json.Unmarshal(byteValue, &generalDeploymentApi)
+			}
+			if generalDeploymentApi.Name == "" || strings.HasPrefix(generalDeploymentApi.PlatformId, "aws-") {
+				// APIs offramped from AWS are already in AWS
+				continue
+			}
+
+			versionName := getGeneralVersionName(f.Name())
+			if generalDeploymentApi.VersionName != "" {
+				versionName = generalDeploymentApi.VersionName
+			}
+			if importedVersions[versionName] {
+				continue
+			}
+
+			spec, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + generalDeploymentApi.Name + "-oas.json")
+			if err != nil || !gjson.GetBytes(spec, "openapi").Exists() {
+				fmt.Println("  >> Skipping " + generalDeploymentApi.Name + ", only OpenAPI 3 specs can be imported.")
+				continue
+			}
+			importedVersions[versionName] = true
+
+			body := string(addAwsIntegrationsToSpec(spec, generalDeploymentApi.GatewayUrl))
+			ledgerKey := flags.Region + "/" + versionName
+			importedApi, found := ledger.Apis[ledgerKey]
+
+			var apiId, apiEndpoint string
+			if found {
+				fmt.Println("Reimporting " + versionName + "...")
+				result, err := client.ReimportApi(context.TODO(), &apigatewayv2.ReimportApiInput{ApiId: &importedApi.ApiId, Body: &body})
+				if err != nil {
+					var notFound *types.NotFoundException
+					if !errors.As(err, &notFound) {
+						fmt.Println(err)
+						continue
+					}
+					// the API was deleted, import it again
+					found = false
+				} else {
+					apiId, apiEndpoint = aws.ToString(result.ApiId), aws.ToString(result.ApiEndpoint)
+				}
+			}
+
+			if !found {
+				fmt.Println("Importing " + versionName + "...")
+				result, err := client.ImportApi(context.TODO(), &apigatewayv2.ImportApiInput{Body: &body})
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				apiId, apiEndpoint = aws.ToString(result.ApiId), aws.ToString(result.ApiEndpoint)
+			}
+
+			_, err = client.GetStage(context.TODO(), &apigatewayv2.GetStageInput{ApiId: &apiId, StageName: &stage})
+			if err != nil {
+				autoDeploy := true
+				_, err = client.CreateStage(context.TODO(), &apigatewayv2.CreateStageInput{ApiId: &apiId, StageName: &stage, AutoDeploy: &autoDeploy})
+				if err != nil {
+					fmt.Println(err)
+				}
+			}
+
+			ledger.Apis[ledgerKey] = AwsImportedApi{ApiId: apiId, Region: flags.Region, Stage: stage, ApiEndpoint: apiEndpoint}
+			bytes, _ := json.MarshalIndent(ledger, "", "  ")
+			os.MkdirAll(filepath.Dir(ledgerFile), 0755)
+			os.WriteFile(ledgerFile, bytes, 0644)
+		}
+	}
 
 	return nil
 }
+
+// addAwsIntegrationsToSpec proxies every operation of a spec to its current gateway url, so the imported API forwards requests.
+func addAwsIntegrationsToSpec(spec []byte, gatewayUrl string) []byte {
+	if gatewayUrl == "" {
+		return spec
+	}
+
+	var document map[string]any
+	if json.Unmarshal(spec, &document) != nil {
+		return spec
+	}
+
+	paths, _ := document["paths"].(map[string]any)
+	for path, pathItem := range paths {
+		operations, _ := pathItem.(map[string]any)
+		for method, operation := range operations {
+			operation, ok := operation.(map[string]any)
+			if !ok || !slices.Contains([]string{"get", "put", "post", "delete", "options", "head", "patch"}, method) {
+				continue
+			}
+			if _, ok := operation["x-amazon-apigateway-integration"]; !ok {
+				operation["x-amazon-apigateway-integration"] = map[string]any{
+					"type":                 "http_proxy",
+					"httpMethod":           strings.ToUpper(method),
+					"uri":                  strings.TrimSuffix(gatewayUrl, "/") + path,
+					"payloadFormatVersion": "1.0",
+				}
+			}
+		}
+	}
+
+	result, err := json.Marshal(document)
+	if err != nil {
+		return spec
+	}
+
+	return result
+}
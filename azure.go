@@ -2,15 +2,30 @@ package main
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tidwall/gjson"
 )
@@ -29,6 +44,42 @@ type AzureServiceProperties struct {
 	PortalUrl          string `json:"portalUrl"`
 	PublisherEmail     string `json:"publisherEmail"`
 	PublisherName      string `json:"publisherName"`
+
+	AdditionalLocations    []AzureServiceLocation       `json:"additionalLocations"`
+	HostnameConfigurations []AzureHostnameConfiguration `json:"hostnameConfigurations"`
+}
+
+type AzureServiceLocation struct {
+	Location           string `json:"location"`
+	GatewayRegionalUrl string `json:"gatewayRegionalUrl"`
+	DisableGateway     bool   `json:"disableGateway"`
+}
+
+type AzureHostnameConfiguration struct {
+	Type     string `json:"type"`
+	HostName string `json:"hostName"`
+}
+
+type AzureGateways struct {
+	Value []AzureGateway `json:"value"`
+}
+
+type AzureGateway struct {
+	Id         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Properties AzureGatewayProperties `json:"properties"`
+	Apis       []string               `json:"apis"`
+	Hostnames  []string               `json:"hostnames"`
+}
+
+type AzureGatewayProperties struct {
+	Description  string `json:"description"`
+	LocationData struct {
+		Name            string `json:"name"`
+		City            string `json:"city"`
+		District        string `json:"district"`
+		CountryOrRegion string `json:"countryOrRegion"`
+	} `json:"locationData"`
 }
 
 type AzureApis struct {
@@ -44,6 +95,7 @@ type AzureApi struct {
 
 type AzureApiProperties struct {
 	DisplayName                   string                                `json:"displayName"`
+	Type                          string                                `json:"type"`
 	ApiRevision                   string                                `json:"apiRevision"`
 	Description                   string                                `json:"description"`
 	SubscriptionRequired          string                                `json:"subscriptionRequired"`
@@ -56,6 +108,47 @@ type AzureApiProperties struct {
 	IsCurrent                     bool                                  `json:"isCurrent"`
 	ApiRevisionDescription        string                                `json:"apiRevisionDescription"`
 	ApiVersion                    string                                `json:"apiVersion"`
+	ApiVersionSetId               string                                `json:"apiVersionSetId,omitempty"`
+	ApiVersionSet                 *AzureApiVersionSet                   `json:"apiVersionSet,omitempty"`
+}
+
+type AzureApiVersionSet struct {
+	Id                string `json:"id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	VersioningScheme  string `json:"versioningScheme"`
+	VersionQueryName  string `json:"versionQueryName"`
+	VersionHeaderName string `json:"versionHeaderName"`
+}
+
+// AzureApiImport is the body to create or update an API in an API Management service.
+type AzureApiImport struct {
+	Properties AzureApiImportProperties `json:"properties"`
+}
+
+type AzureApiImportProperties struct {
+	DisplayName          string   `json:"displayName"`
+	Description          string   `json:"description,omitempty"`
+	Path                 string   `json:"path"`
+	ServiceUrl           string   `json:"serviceUrl,omitempty"`
+	Protocols            []string `json:"protocols"`
+	SubscriptionRequired bool     `json:"subscriptionRequired"`
+	ApiVersion           string   `json:"apiVersion,omitempty"`
+	ApiVersionSetId      string   `json:"apiVersionSetId,omitempty"`
+	Format               string   `json:"format,omitempty"`
+	Value                string   `json:"value,omitempty"`
+}
+
+type AzureApiVersionSetImport struct {
+	Properties AzureApiVersionSetImportProperties `json:"properties"`
+}
+
+type AzureApiVersionSetImportProperties struct {
+	DisplayName       string `json:"displayName"`
+	Description       string `json:"description,omitempty"`
+	VersioningScheme  string `json:"versioningScheme"`
+	VersionQueryName  string `json:"versionQueryName,omitempty"`
+	VersionHeaderName string `json:"versionHeaderName,omitempty"`
 }
 
 type AzureApiAuthenticationSettings struct {
@@ -70,19 +163,108 @@ type AzureApiSubscriptionKeyParameterNames struct {
 	Query  string `json:"query"`
 }
 
-type AzureApiSchema struct {
-	Id         string                   `json:"id"`
-	Type       string                   `json:"type"`
-	Name       string                   `json:"name"`
-	Properties AzureApiSchemaProperties `json:"properties"`
+type AzureApiRevisions struct {
+	Value []AzureApiRevision `json:"value"`
+}
+
+type AzureApiRevision struct {
+	ApiId           string `json:"apiId"`
+	ApiRevision     string `json:"apiRevision"`
+	CreatedDateTime string `json:"createdDateTime"`
+	UpdatedDateTime string `json:"updatedDateTime"`
+	Description     string `json:"description"`
+	PrivateUrl      string `json:"privateUrl"`
+	IsOnline        bool   `json:"isOnline"`
+	IsCurrent       bool   `json:"isCurrent"`
+}
+
+type AzureProducts struct {
+	Value    []AzureProduct `json:"value"`
+	NextLink string         `json:"nextLink"`
+}
+
+type AzureProduct struct {
+	Id                string                 `json:"id"`
+	Name              string                 `json:"name"`
+	Properties        AzureProductProperties `json:"properties"`
+	Apis              []string               `json:"apis"`
+	SubscriptionCount int                    `json:"subscriptionCount"`
+}
+
+type AzureProductProperties struct {
+	DisplayName          string `json:"displayName"`
+	Description          string `json:"description"`
+	Terms                string `json:"terms"`
+	SubscriptionRequired bool   `json:"subscriptionRequired"`
+	ApprovalRequired     bool   `json:"approvalRequired"`
+	SubscriptionsLimit   int    `json:"subscriptionsLimit"`
+	State                string `json:"state"`
 }
 
-type AzureApiSchemaProperties struct {
-	Description string `json:"description"`
-	SchemaType  string `json:"schemaType"`
-	Document    string `json:"document"`
+type AzureApiOperations struct {
+	Value    []AzureApiOperation `json:"value"`
+	NextLink string              `json:"nextLink"`
 }
 
+type AzureApiOperation struct {
+	Id         string                      `json:"id"`
+	Name       string                      `json:"name"`
+	Properties AzureApiOperationProperties `json:"properties"`
+}
+
+type AzureApiOperationProperties struct {
+	DisplayName        string                   `json:"displayName"`
+	Method             string                   `json:"method"`
+	UrlTemplate        string                   `json:"urlTemplate"`
+	Description        string                   `json:"description"`
+	TemplateParameters []AzureParameter         `json:"templateParameters"`
+	Request            AzureOperationRequest    `json:"request"`
+	Responses          []AzureOperationResponse `json:"responses"`
+}
+
+type AzureParameter struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Type         string   `json:"type"`
+	DefaultValue string   `json:"defaultValue"`
+	Required     bool     `json:"required"`
+	Values       []string `json:"values"`
+}
+
+type AzureOperationRequest struct {
+	Description     string                `json:"description"`
+	QueryParameters []AzureParameter      `json:"queryParameters"`
+	Headers         []AzureParameter      `json:"headers"`
+	Representations []AzureRepresentation `json:"representations"`
+}
+
+type AzureOperationResponse struct {
+	StatusCode      int                   `json:"statusCode"`
+	Description     string                `json:"description"`
+	Headers         []AzureParameter      `json:"headers"`
+	Representations []AzureRepresentation `json:"representations"`
+}
+
+type AzureRepresentation struct {
+	ContentType string `json:"contentType"`
+	SchemaId    string `json:"schemaId"`
+	TypeName    string `json:"typeName"`
+}
+
+type AzureSubscriptions struct {
+	Value    []map[string]any `json:"value"`
+	NextLink string           `json:"nextLink"`
+}
+
+// formats to export API specs in, in order of preference
+var azureExportFormats = []string{"openapi+json", "openapi+json-link", "swagger-link-json", "wsdl-link+xml"}
+
+// SOAP pass-through APIs can only be exported as WSDL
+var azureSoapExportFormats = []string{"wsdl-link+xml"}
+
+// files stored next to an exported API that are not API definitions
+var azureSupportFileSuffixes = []string{"-oas.json", "-oas-definition.json", "-revisions.json", "-operations.json", "-asyncapi.json"}
+
 type AzureTokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	ExpiresIn    string `json:"expires_in"`
@@ -100,49 +282,55 @@ type AzureFlags struct {
 	Token         string `name:"token" description:"The Azure access token to call Azure with."`
 	ApiName       string `name:"api" description:"A specific Azure API Management API."`
 	OnlyNew       bool   `name:"onlyNew" description:"If only newly discovered APIs should be processed."`
+	Revisions     bool   `name:"revisions" description:"If the revision history of APIs should be exported."`
+	Counts        bool   `name:"subscriptionCounts" description:"If the number of subscriptions of each product should be exported."`
+	Policies      bool   `name:"policies" description:"If the policy XML of the service, products, APIs and operations should be exported."`
+	Subscriptions string `name:"subscriptions" description:"Comma-separated subscription IDs, or all, to discover API Management services in."`
 }
 
 func azureStatus(flags *AzureFlags) PlatformStatus {
 	var status PlatformStatus
-	var token string = flags.Token
-	if flags.Subscription == "" {
+	if flags.Subscriptions == "" && flags.Subscription == "" {
 		status.Connected = false
 		status.Message = "No subscription given, cannot connect to Azure API Management."
 		return status
-	} else if flags.ResourceGroup == "" {
+	} else if flags.Subscriptions == "" && flags.ResourceGroup == "" {
 		status.Connected = false
 		status.Message = "No resource group given, cannot connect to Azure API Management."
 		return status
-	} else if flags.ServiceName == "" {
+	} else if flags.Subscriptions == "" && flags.ServiceName == "" {
 		status.Connected = false
 		status.Message = "No service name given, cannot connect to Azure API Management."
 		return status
 	}
 
+	token := getAzureAccessToken(flags)
 	if token == "" {
-		// fetch an Azure token using a client id and secret
-		var env_token string = os.Getenv("AZURE_TOKEN")
-		if env_token != "" {
-			token = env_token
-		} else {
-			var client_id string = os.Getenv("AZURE_CLIENT_ID")
-			var client_secret string = os.Getenv("AZURE_CLIENT_SECRET")
-			var tenant_id string = os.Getenv("AZURE_TENANT_ID")
-
-			if client_id == "" || client_secret == "" || tenant_id == "" {
-				status.Connected = false
-				status.Message = "No client id, secret or tenant id give, cannot get Azure token."
-				return status
-			}
+		status.Connected = false
+		status.Message = "Could not get Azure token."
+		return status
+	}
 
-			token = getAzureToken(client_id, client_secret, tenant_id)
+	if flags.Subscriptions != "" {
+		// discovery mode, report counts per service
+		total := 0
+		counts := []string{}
+		for _, service := range getAzureDiscoveredServices(flags, token) {
+			subscriptionId, resourceGroup := getAzureResourceScope(service.Id)
+			apis := getAzureApis(subscriptionId, resourceGroup, service.Name, token)
+			total += len(apis.Value)
+			counts = append(counts, service.Name+": "+strconv.Itoa(len(apis.Value)))
 		}
 
-		if token == "" {
+		if len(counts) > 0 {
+			status.Connected = true
+			status.Message = "Connected to Azure, " + strconv.Itoa(total) + " APIs found (" + strings.Join(counts, ", ") + ")."
+		} else {
 			status.Connected = false
-			status.Message = "Could not get Azure token."
-			return status
+			status.Message = "No Azure API Management services found in subscriptions " + flags.Subscriptions + "."
 		}
+
+		return status
 	}
 
 	var apis AzureApis
@@ -172,6 +360,40 @@ func azureStatus(flags *AzureFlags) PlatformStatus {
 	return status
 }
 
+func azureDiscoverMin(flags *AzureFlags) error {
+	azureDiscover(flags)
+	return nil
+}
+
+func azureDiscover(flags *AzureFlags) ([]string, error) {
+	apiNames := []string{}
+	token := getAzureAccessToken(flags)
+	if token == "" {
+		fmt.Println("Could not get valid Azure token, cannot discover Azure APIs.")
+		return apiNames, nil
+	}
+
+	services := getAzureDiscoveredServices(flags, token)
+	if len(services) == 0 {
+		fmt.Println("No Azure API Management services found, cannot discover APIs.")
+		return apiNames, nil
+	}
+
+	for _, service := range services {
+		serviceFlags := *flags
+		serviceFlags.Subscription, serviceFlags.ResourceGroup = getAzureResourceScope(service.Id)
+		serviceFlags.ServiceName = service.Name
+
+		// each service is exported to its own namespace in the local store
+		baseDir := "src/main/azure/subscriptions/" + serviceFlags.Subscription + "/" + serviceFlags.ResourceGroup + "/" + service.Name
+		azureExportService(&serviceFlags, token, baseDir)
+		apiNames = append(apiNames, azureExportApis(&serviceFlags, token, baseDir+"/apiproxies")...)
+		azureExportProducts(&serviceFlags, token, baseDir+"/products")
+	}
+
+	return apiNames, nil
+}
+
 func azureCleanLocal(flags *AzureFlags) error {
 	var baseDir = "src/main/azure"
 	os.RemoveAll(baseDir)
@@ -180,7 +402,6 @@ func azureCleanLocal(flags *AzureFlags) error {
 
 func azureServiceExport(flags *AzureFlags) error {
 	var baseDir = "src/main/azure"
-	var token string = flags.Token
 	if flags.Subscription == "" {
 		fmt.Println("No subscription given, cannot export Azure APIs.")
 		return nil
@@ -192,30 +413,18 @@ func azureServiceExport(flags *AzureFlags) error {
 		return nil
 	}
 
+	token := getAzureAccessToken(flags)
 	if token == "" {
-		// fetch an Azure token using a client id and secret
-		var env_token string = os.Getenv("AZURE_TOKEN")
-		if env_token != "" {
-			token = env_token
-		} else {
-			var client_id string = os.Getenv("AZURE_CLIENT_ID")
-			var client_secret string = os.Getenv("AZURE_CLIENT_SECRET")
-			var tenant_id string = os.Getenv("AZURE_TENANT_ID")
-
-			if client_id == "" || client_secret == "" || tenant_id == "" {
-				fmt.Println("No token sent and no client environment variables set, cannot export Azure APIs.")
-				return nil
-			}
+		fmt.Println("Could not get valid Azure token, cannot export Azure APIs.")
+		return nil
+	}
 
-			token = getAzureToken(client_id, client_secret, tenant_id)
-		}
+	azureExportService(flags, token, baseDir)
 
-		if token == "" {
-			fmt.Println("Could not get valid Azure token, cannot export Azure APIs.")
-			return nil
-		}
-	}
+	return nil
+}
 
+func azureExportService(flags *AzureFlags, token string, baseDir string) {
 	fmt.Println("Exporting Azure service " + flags.ServiceName + "...")
 	service := getAzureService(flags.Subscription, flags.ResourceGroup, flags.ServiceName, token)
 	if service != "" {
@@ -228,7 +437,29 @@ func azureServiceExport(flags *AzureFlags) error {
 		os.WriteFile(baseDir+"/"+flags.ServiceName+".json", bytes2, 0644)
 	}
 
-	return nil
+	// self-hosted gateways, with the APIs they serve
+	gateways := getAzureGateways(flags.Subscription, flags.ResourceGroup, flags.ServiceName, token)
+	for _, gateway := range gateways.Value {
+		fmt.Println("Exporting gateway " + gateway.Name + "...")
+		gatewayUrl := getAzureServiceUrl(flags.Subscription, flags.ResourceGroup, flags.ServiceName) + "/gateways/" + gateway.Name
+		gateway.Apis = []string{}
+		for _, api := range getAzureApisFromUrl(gatewayUrl+"/apis?api-version=2022-08-01", token).Value {
+			gateway.Apis = append(gateway.Apis, api.Name)
+		}
+		gateway.Hostnames = getAzureGatewayHostnames(gatewayUrl, token)
+
+		bytes, _ := json.MarshalIndent(gateway, "", "  ")
+		os.MkdirAll(baseDir+"/gateways", 0755)
+		os.WriteFile(baseDir+"/gateways/"+gateway.Name+".json", bytes, 0644)
+	}
+
+	if flags.Policies {
+		policy := getAzurePolicyXml(getAzureServiceUrl(flags.Subscription, flags.ResourceGroup, flags.ServiceName), token)
+		if policy != "" {
+			os.MkdirAll(baseDir+"/policies", 0755)
+			os.WriteFile(baseDir+"/policies/global.xml", []byte(policy), 0644)
+		}
+	}
 }
 
 func azureExportMin(flags *AzureFlags) error {
@@ -238,7 +469,6 @@ func azureExportMin(flags *AzureFlags) error {
 
 func azureExport(flags *AzureFlags) ([]string, error) {
 	var baseDir = "src/main/azure/apiproxies"
-	var token string = flags.Token
 	if flags.Subscription == "" {
 		fmt.Println("No subscription given, cannot export Azure APIs.")
 		return []string{}, nil
@@ -250,30 +480,16 @@ func azureExport(flags *AzureFlags) ([]string, error) {
 		return []string{}, nil
 	}
 
+	token := getAzureAccessToken(flags)
 	if token == "" {
-		// fetch an Azure token using a client id and secret
-		var env_token string = os.Getenv("AZURE_TOKEN")
-		if env_token != "" {
-			token = env_token
-		} else {
-			var client_id string = os.Getenv("AZURE_CLIENT_ID")
-			var client_secret string = os.Getenv("AZURE_CLIENT_SECRET")
-			var tenant_id string = os.Getenv("AZURE_TENANT_ID")
-
-			if client_id == "" || client_secret == "" || tenant_id == "" {
-				fmt.Println("No token sent and no client environment variables set, cannot export Azure APIs.")
-				return []string{}, nil
-			}
-
-			token = getAzureToken(client_id, client_secret, tenant_id)
-		}
-
-		if token == "" {
-			fmt.Println("Could not get valid Azure token, cannot export Azure APIs.")
-			return []string{}, nil
-		}
+		fmt.Println("Could not get valid Azure token, cannot export Azure APIs.")
+		return []string{}, nil
 	}
 
+	return azureExportApis(flags, token, baseDir), nil
+}
+
+func azureExportApis(flags *AzureFlags, token string, baseDir string) []string {
 	fmt.Println("Exporting Azure APIs for service " + flags.ServiceName + "...")
 	apis := getAzureApis(flags.Subscription, flags.ResourceGroup, flags.ServiceName, token)
 	apiNames := []string{}
@@ -282,8 +498,8 @@ func azureExport(flags *AzureFlags) ([]string, error) {
 			if (flags.ApiName == "" || flags.ApiName == api.Name) && !strings.Contains(api.Name, ";rev=") {
 				fmt.Println("Exporting " + api.Name + "...")
 
-				var re = regexp.MustCompile(`(-v\d+)$`)
-				newName := re.ReplaceAllString(api.Name, "")
+				newName := getAzureApiGroupName(api)
+				azureApiName := api.Name
 				newApiName := api.Name
 				if api.Properties.ApiVersion != "" && !strings.HasSuffix(newApiName, api.Properties.ApiVersion) {
 					newApiName = api.Name + "-" + api.Properties.ApiVersion
@@ -302,14 +518,64 @@ func azureExport(flags *AzureFlags) ([]string, error) {
 
 					os.MkdirAll(baseDir+"/"+newName, 0755)
 					os.WriteFile(baseDir+"/"+newName+"/"+newApiName+".json", bytes, 0644)
-					schema := getAzureApiSchema(flags.Subscription, flags.ResourceGroup, flags.ServiceName, newApiName, token)
+					operations := getAzureApiOperations(flags.Subscription, flags.ResourceGroup, flags.ServiceName, azureApiName, token)
+					if len(operations.Value) > 0 {
+						bytes, _ := json.MarshalIndent(operations, "", "  ")
+						os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-operations.json", bytes, 0644)
+					}
+
+					switch api.Properties.Type {
+					case "graphql":
+						schema := getAzureApiGraphqlSchema(flags.Subscription, flags.ResourceGroup, flags.ServiceName, azureApiName, token)
+						if schema != "" {
+							os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-schema.graphql", []byte(schema), 0644)
+						} else {
+							fmt.Println("  >> No GraphQL schema could be exported for " + azureApiName + ".")
+						}
+					case "websocket":
+						// websocket APIs have no exportable spec, describe them as an AsyncAPI document
+						os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-asyncapi.json", getAzureWebSocketSpec(api), 0644)
+					default:
+						formats := azureExportFormats
+						if api.Properties.Type == "soap" {
+							formats = azureSoapExportFormats
+						}
+						spec, format := getAzureApiExport(flags.Subscription, flags.ResourceGroup, flags.ServiceName, azureApiName, formats, token)
+
+						if spec != nil {
+							if strings.HasPrefix(format, "wsdl") {
+								os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-wsdl.xml", spec, 0644)
+							} else {
+								os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-oas.json", spec, 0644)
+							}
+						} else if len(operations.Value) > 0 && api.Properties.Type != "soap" {
+							// APIs created in the portal may have no importable schema, so build a skeleton spec from their operations
+							fmt.Println("  >> No spec could be exported for " + azureApiName + ", creating one from its operations.")
+							os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-oas.json", getAzureOperationsSpec(api, operations), 0644)
+						} else {
+							fmt.Println("  >> No spec could be exported for " + azureApiName + ".")
+						}
+					}
+
+					if flags.Revisions {
+						revisions := getAzureApiRevisions(flags.Subscription, flags.ResourceGroup, flags.ServiceName, azureApiName, token)
+						bytes, _ := json.MarshalIndent(revisions, "", "  ")
+						os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-revisions.json", bytes, 0644)
+					}
 
-					if schema.Id != "" {
-						bytes, _ := json.MarshalIndent(schema, "", "  ")
-						os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-oas-definition.json", bytes, 0644)
+					if flags.Policies {
+						apiUrl := getAzureServiceUrl(flags.Subscription, flags.ResourceGroup, flags.ServiceName) + "/apis/" + azureApiName
+						policy := getAzurePolicyXml(apiUrl, token)
+						if policy != "" {
+							os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-policy.xml", []byte(policy), 0644)
+						}
 
-						doc_bytes := []byte(schema.Properties.Document)
-						os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-oas."+schema.Properties.SchemaType, doc_bytes, 0644)
+						for _, operation := range operations.Value {
+							policy := getAzurePolicyXml(apiUrl+"/operations/"+operation.Name, token)
+							if policy != "" {
+								os.WriteFile(baseDir+"/"+newName+"/"+newApiName+"-policy-"+operation.Name+".xml", []byte(policy), 0644)
+							}
+						}
 					}
 
 					apiNames = append(apiNames, api.Name)
@@ -318,125 +584,835 @@ func azureExport(flags *AzureFlags) ([]string, error) {
 		}
 	}
 
-	return apiNames, nil
+	return apiNames
+}
+
+func azureProductsExportMin(flags *AzureFlags) error {
+	azureProductsExport(flags)
+	return nil
+}
+
+func azureProductsExport(flags *AzureFlags) ([]string, error) {
+	var baseDir = "src/main/azure/products"
+	if flags.Subscription == "" {
+		fmt.Println("No subscription given, cannot export Azure products.")
+		return []string{}, nil
+	} else if flags.ResourceGroup == "" {
+		fmt.Println("No resource group given, cannot export Azure products.")
+		return []string{}, nil
+	} else if flags.ServiceName == "" {
+		fmt.Println("No service name given, cannot export Azure products.")
+		return []string{}, nil
+	}
+
+	token := getAzureAccessToken(flags)
+	if token == "" {
+		fmt.Println("Could not get valid Azure token, cannot export Azure products.")
+		return []string{}, nil
+	}
+
+	return azureExportProducts(flags, token, baseDir), nil
+}
+
+func azureExportProducts(flags *AzureFlags, token string, baseDir string) []string {
+	fmt.Println("Exporting Azure products for service " + flags.ServiceName + "...")
+
+	// products link to API names, keep the group names the APIs are exported under
+	groupNames := make(map[string]string)
+	for _, api := range getAzureApis(flags.Subscription, flags.ResourceGroup, flags.ServiceName, token).Value {
+		groupNames[api.Name] = getAzureApiGroupName(api)
+	}
+
+	productNames := []string{}
+	products := getAzureProducts(flags.Subscription, flags.ResourceGroup, flags.ServiceName, token)
+	for _, product := range products.Value {
+		fmt.Println("Exporting " + product.Name + "...")
+
+		product.Apis = []string{}
+		for _, api := range getAzureProductApis(flags.Subscription, flags.ResourceGroup, flags.ServiceName, product.Name, token).Value {
+			groupName, ok := groupNames[api.Name]
+			if !ok {
+				groupName = getAzureApiGroupName(api)
+			}
+			if !slices.Contains(product.Apis, groupName) {
+				product.Apis = append(product.Apis, groupName)
+			}
+		}
+
+		if flags.Counts {
+			product.SubscriptionCount = getAzureProductSubscriptionCount(flags.Subscription, flags.ResourceGroup, flags.ServiceName, product.Name, token)
+		}
+
+		bytes, _ := json.MarshalIndent(product, "", "  ")
+		os.MkdirAll(baseDir, 0755)
+		os.WriteFile(baseDir+"/"+product.Name+".json", bytes, 0644)
+
+		if flags.Policies {
+			policy := getAzurePolicyXml(getAzureServiceUrl(flags.Subscription, flags.ResourceGroup, flags.ServiceName)+"/products/"+product.Name, token)
+			if policy != "" {
+				os.WriteFile(baseDir+"/"+product.Name+"-policy.xml", []byte(policy), 0644)
+			}
+		}
+
+		productNames = append(productNames, product.Name)
+	}
+
+	return productNames
+}
+
+func azureProductsOfframp(flags *AzureFlags) error {
+	azureProductsDir := "src/main/azure/products"
+
+	// discovered services are stored per subscription and resource group
+	stores, _ := filepath.Glob("src/main/azure/subscriptions/*/*/*")
+
+	_, err := os.ReadDir(azureProductsDir)
+	if err != nil && len(stores) == 0 {
+		fmt.Println("No exported Azure products found, nothing to offramp.")
+		return nil
+	}
+
+	fmt.Println("Offramping Azure API Management products to general...")
+
+	azureOfframpProducts(azureProductsDir, "")
+	for _, store := range stores {
+		azureOfframpProducts(store+"/products", filepath.Base(store))
+	}
+
+	return nil
+}
+
+func azureOfframpProducts(azureProductsDir string, namespace string) {
+	entries, _ := os.ReadDir(azureProductsDir)
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		var product AzureProduct
+		byteValue, err := os.ReadFile(azureProductsDir + "/" + e.Name())
+		if err == nil {
+			json.Unmarshal(byteValue, &product)
+		}
+
+		if product.Name != "" {
+			fmt.Println(product.Name)
+
+			var generalProduct GeneralProduct
+			generalProduct.Name = product.Name + "-azure"
+			if namespace != "" {
+				generalProduct.Name = product.Name + "-" + namespace + "-azure"
+			}
+			generalProduct.DisplayName = product.Properties.DisplayName
+			generalProduct.Description = product.Properties.Description
+			generalProduct.Apis = product.Apis
+			generalProduct.ApprovalRequired = product.Properties.ApprovalRequired
+			generalProduct.SubscriptionsLimit = product.Properties.SubscriptionsLimit
+			generalProduct.Subscriptions = product.SubscriptionCount
+			generalProduct.PlatformId = "azure-api-management"
+			generalProduct.PlatformName = "Azure API Management"
+			generalProduct.PlatformResourceUri = "https://portal.azure.com/#resource" + product.Id
+
+			writeGeneralProduct(generalProduct)
+		}
+	}
+}
+
+// the resource Azure management tokens are requested for
+const azureManagementResource = "https://management.azure.com/"
+
+// getAzureAccessToken returns the token flag if set, otherwise the first token from the Azure credential chain:
+// a static AZURE_TOKEN, a client secret, a client certificate, a federated workload identity token,
+// a managed identity and finally the Azure CLI.
+func getAzureAccessToken(flags *AzureFlags) string {
+	if flags.Token != "" {
+		return flags.Token
+	} else if token := os.Getenv("AZURE_TOKEN"); token != "" {
+		return token
+	}
+
+	clientId := os.Getenv("AZURE_CLIENT_ID")
+	tenantId := os.Getenv("AZURE_TENANT_ID")
+	if clientId != "" && tenantId != "" {
+		if clientSecret := os.Getenv("AZURE_CLIENT_SECRET"); clientSecret != "" {
+			return getAzureToken(clientId, clientSecret, tenantId)
+		} else if certificatePath := os.Getenv("AZURE_CLIENT_CERTIFICATE_PATH"); certificatePath != "" {
+			assertion, err := getAzureCertificateAssertion(clientId, tenantId, certificatePath)
+			if err != nil {
+				fmt.Println("Could not create Azure client certificate assertion: " + err.Error())
+				return ""
+			}
+			return getAzureAssertionToken(clientId, tenantId, assertion)
+		} else if tokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tokenFile != "" {
+			assertion, err := os.ReadFile(tokenFile)
+			if err != nil {
+				fmt.Println("Could not read Azure federated token file: " + err.Error())
+				return ""
+			}
+			return getAzureAssertionToken(clientId, tenantId, strings.TrimSpace(string(assertion)))
+		} else if assertion := getGoogleIdentityToken("api://AzureADTokenExchange"); assertion != "" {
+			// running on Google Cloud, federate the service account identity
+			return getAzureAssertionToken(clientId, tenantId, assertion)
+		}
+	}
+
+	if token := getAzureManagedIdentityToken(clientId); token != "" {
+		return token
+	}
+
+	return getAzureCliToken()
 }
 
+// getAzureToken fetches an Azure token using a client id and secret.
 func getAzureToken(clientId string, clientSecret string, tenantId string) string {
-	var result string = ""
-	var body string = "grant_type=client_credentials&client_id=" + clientId + "&client_secret=" + clientSecret + "&resource=https%3A%2F%2Fmanagement.azure.com%2F"
-	bodyBuffer := bytes.NewBufferString(body)
-	req, _ := http.NewRequest(http.MethodPost, "https://login.microsoftonline.com/"+tenantId+"/oauth2/token", bodyBuffer)
-	response, err := http.DefaultClient.Do(req)
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", clientId)
+	form.Set("client_secret", clientSecret)
+	return getAzureTenantToken(tenantId, form)
+}
+
+// getAzureAssertionToken fetches an Azure token using a signed client assertion or a federated token.
+func getAzureAssertionToken(clientId string, tenantId string, assertion string) string {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", clientId)
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", assertion)
+	return getAzureTenantToken(tenantId, form)
+}
 
-	//Handle Error
+func getAzureTenantToken(tenantId string, form url.Values) string {
+	var result string = ""
+	form.Set("resource", azureManagementResource)
+	response, err := http.PostForm("https://login.microsoftonline.com/"+url.PathEscape(tenantId)+"/oauth2/token", form)
 	if err != nil {
-		log.Fatalf("An Error Occured %v", err)
+		fmt.Println("Could not get Azure token: " + err.Error())
+		return result
 	}
 	defer response.Body.Close()
-	//Read the response body
+
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		log.Fatalln(err)
+		fmt.Println("Could not get Azure token: " + err.Error())
+		return result
 	}
 	var azureToken AzureTokenResponse
 	json.Unmarshal(responseBody, &azureToken)
 
 	if azureToken.AccessToken != "" {
 		result = azureToken.AccessToken
+	} else {
+		fmt.Println("Could not get Azure token: " + gjson.GetBytes(responseBody, "error_description").String())
 	}
 
 	return result
 }
 
-func getAzureService(subscriptionId string, resourceGroup string, serviceName string, token string) string {
-	//var service AzureService
-	var service string
-	req, _ := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/"+subscriptionId+"/resourceGroups/"+resourceGroup+"/providers/Microsoft.ApiManagement/service/"+serviceName+"?api-version=2022-08-01", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+// getAzureCertificateAssertion signs a client assertion with the certificate and private key in a PEM file.
+func getAzureCertificateAssertion(clientId string, tenantId string, certificatePath string) (string, error) {
+	pemBytes, err := os.ReadFile(certificatePath)
+	if err != nil {
+		return "", err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err == nil {
-		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			service = string(body)
-			//json.Unmarshal(body, &service)
-			//fmt.Println(string(body))
+	var certificate *x509.Certificate
+	var privateKey *rsa.PrivateKey
+	for block, rest := pem.Decode(pemBytes); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			if certificate == nil {
+				certificate, err = x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return "", err
+				}
+			}
+		case "RSA PRIVATE KEY":
+			privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return "", err
+			}
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return "", err
+			}
+			rsaKey, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return "", errors.New("only RSA private keys are supported")
+			}
+			privateKey = rsaKey
 		}
 	}
 
-	return service
+	if certificate == nil || privateKey == nil {
+		return "", errors.New("the PEM file must contain a certificate and an unencrypted private key")
+	}
+
+	thumbprint := sha1.Sum(certificate.Raw)
+	jti := make([]byte, 16)
+	rand.Read(jti)
+	now := time.Now().Unix()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "x5t": base64.RawURLEncoding.EncodeToString(thumbprint[:])})
+	claims, _ := json.Marshal(map[string]any{
+		"aud": "https://login.microsoftonline.com/" + tenantId + "/oauth2/token",
+		"iss": clientId,
+		"sub": clientId,
+		"jti": hex.EncodeToString(jti),
+		"nbf": now,
+		"exp": now + 600,
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
 }
 
-func getAzureApis(subscriptionId string, resourceGroup string, serviceName string, token string) AzureApis {
-	var apis AzureApis
-	req, _ := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/"+subscriptionId+"/resourceGroups/"+resourceGroup+"/providers/Microsoft.ApiManagement/service/"+serviceName+"/apis?api-version=2022-08-01", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+// getGoogleIdentityToken fetches a Google service account identity token from the metadata server, if running on Google Cloud.
+func getGoogleIdentityToken(audience string) string {
+	var result string
+	client := http.Client{Timeout: 2 * time.Second}
+	req, _ := http.NewRequest(http.MethodGet, "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/identity?audience="+url.QueryEscape(audience), nil)
+	req.Header.Add("Metadata-Flavor", "Google")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err == nil {
+		defer resp.Body.Close()
 		body, err := io.ReadAll(resp.Body)
-		if err == nil {
-			json.Unmarshal(body, &apis)
-			//fmt.Println(string(body))
+		if err == nil && resp.StatusCode == 200 {
+			result = strings.TrimSpace(string(body))
 		}
 	}
 
-	return apis
+	return result
 }
 
-func getAzureApiSchema(subscriptionId string, resourceGroup string, serviceName string, apiName string, token string) AzureApiSchema {
-	var schema AzureApiSchema
-	req, _ := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/"+subscriptionId+"/resourceGroups/"+resourceGroup+"/providers/Microsoft.ApiManagement/service/"+serviceName+"/schemas/"+apiName+"?api-version=2022-08-01", nil)
-	req.Header.Add("Authorization", "Bearer "+token)
+// getAzureManagedIdentityToken fetches a token for the managed identity of the Azure host, using
+// the identity endpoint of App Service and Container Apps or the instance metadata service.
+func getAzureManagedIdentityToken(clientId string) string {
+	var result string
+	client := http.Client{Timeout: 2 * time.Second}
+	query := "resource=" + url.QueryEscape(azureManagementResource)
+	if clientId != "" {
+		query = query + "&client_id=" + url.QueryEscape(clientId)
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	var req *http.Request
+	if endpoint, header := os.Getenv("IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_HEADER"); endpoint != "" && header != "" {
+		req, _ = http.NewRequest(http.MethodGet, endpoint+"?api-version=2019-08-01&"+query, nil)
+		req.Header.Add("X-IDENTITY-HEADER", header)
+	} else {
+		req, _ = http.NewRequest(http.MethodGet, "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&"+query, nil)
+		req.Header.Add("Metadata", "true")
+	}
+
+	resp, err := client.Do(req)
 	if err == nil {
-		if resp.StatusCode == 200 {
-			body, err := io.ReadAll(resp.Body)
-			if err == nil {
-				document := gjson.Get(string(body), "properties.document").String()
-				json.Unmarshal(body, &schema)
-				schema.Properties.Document = document
-				//fmt.Println(string(body))
-			}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && resp.StatusCode == 200 {
+			result = gjson.GetBytes(body, "access_token").String()
 		}
 	}
 
-	return schema
+	return result
 }
 
-func azureOfframp(flags *AzureFlags) error {
-
-	azureBaseDir := "src/main/azure/apiproxies"
-	baseDir := "src/main/general/apiproxies"
-
-	if flags.Subscription == "" {
-		fmt.Println("No subscription given, cannot offramp Azure APIs.")
-		return nil
-	} else if flags.ResourceGroup == "" {
-		fmt.Println("No resource group given, cannot offramp Azure APIs.")
-		return nil
-	} else if flags.ServiceName == "" {
-		fmt.Println("No service name given, cannot offramp Azure APIs.")
-		return nil
+// getAzureCliToken gets a token from the cached login of the Azure CLI, if it is installed.
+func getAzureCliToken() string {
+	var result string
+	if _, err := exec.LookPath("az"); err != nil {
+		return result
 	}
 
-	entries, err := os.ReadDir(azureBaseDir)
-	if err != nil {
-		log.Fatal(err)
+	output, err := exec.Command("az", "account", "get-access-token", "--resource", azureManagementResource, "--output", "json").Output()
+	if err == nil {
+		result = gjson.GetBytes(output, "accessToken").String()
 	}
 
-	fmt.Println("Offramping Azure API Management APIs to general...")
+	return result
+}
 
-	// load azureService info, if available
-	var azureService AzureService
-	azureServiceFile, err := os.Open(azureBaseDir + "/../" + flags.ServiceName + ".json")
+func getAzureService(subscriptionId string, resourceGroup string, serviceName string, token string) string {
+	return string(getAzureResource(getAzureServiceUrl(subscriptionId, resourceGroup, serviceName)+"?api-version=2022-08-01", token))
+}
 
-	if err == nil {
+func getAzureApis(subscriptionId string, resourceGroup string, serviceName string, token string) AzureApis {
+	var apis AzureApis
+	json.Unmarshal(getAzureResource(getAzureServiceUrl(subscriptionId, resourceGroup, serviceName)+"/apis?expandApiVersionSet=true&api-version=2022-08-01", token), &apis)
+
+	return apis
+}
+
+func getAzureProducts(subscriptionId string, resourceGroup string, serviceName string, token string) AzureProducts {
+	var products AzureProducts
+	nextLink := getAzureServiceUrl(subscriptionId, resourceGroup, serviceName) + "/products?api-version=2022-08-01"
+
+	for nextLink != "" {
+		var page AzureProducts
+		json.Unmarshal(getAzureResource(nextLink, token), &page)
+		products.Value = append(products.Value, page.Value...)
+		nextLink = page.NextLink
+	}
+
+	return products
+}
+
+func getAzureProductApis(subscriptionId string, resourceGroup string, serviceName string, productName string, token string) AzureApis {
+	return getAzureApisFromUrl(getAzureServiceUrl(subscriptionId, resourceGroup, serviceName)+"/products/"+productName+"/apis?api-version=2022-08-01", token)
+}
+
+func getAzureApisFromUrl(apisUrl string, token string) AzureApis {
+	var apis AzureApis
+	json.Unmarshal(getAzureResource(apisUrl, token), &apis)
+
+	return apis
+}
+
+func getAzureProductSubscriptionCount(subscriptionId string, resourceGroup string, serviceName string, productName string, token string) int {
+	count := 0
+	nextLink := getAzureServiceUrl(subscriptionId, resourceGroup, serviceName) + "/products/" + productName + "/subscriptions?api-version=2022-08-01"
+
+	for nextLink != "" {
+		var page AzureSubscriptions
+		json.Unmarshal(getAzureResource(nextLink, token), &page)
+		count += len(page.Value)
+		nextLink = page.NextLink
+	}
+
+	return count
+}
+
+func getAzureApiOperations(subscriptionId string, resourceGroup string, serviceName string, apiName string, token string) AzureApiOperations {
+	var operations AzureApiOperations
+	nextLink := getAzureServiceUrl(subscriptionId, resourceGroup, serviceName) + "/apis/" + apiName + "/operations?api-version=2022-08-01"
+
+	for nextLink != "" {
+		var page AzureApiOperations
+		json.Unmarshal(getAzureResource(nextLink, token), &page)
+		operations.Value = append(operations.Value, page.Value...)
+		nextLink = page.NextLink
+	}
+
+	return operations
+}
+
+func getAzureGateways(subscriptionId string, resourceGroup string, serviceName string, token string) AzureGateways {
+	var gateways AzureGateways
+	json.Unmarshal(getAzureResource(getAzureServiceUrl(subscriptionId, resourceGroup, serviceName)+"/gateways?api-version=2022-08-01", token), &gateways)
+
+	return gateways
+}
+
+func getAzureGatewayHostnames(gatewayUrl string, token string) []string {
+	hostnames := []string{}
+	for _, hostname := range gjson.GetBytes(getAzureResource(gatewayUrl+"/hostnameConfigurations?api-version=2022-08-01", token), "value.#.properties.hostname").Array() {
+		hostnames = append(hostnames, hostname.String())
+	}
+
+	return hostnames
+}
+
+// getAzureDiscoveredServices lists the API Management services in the subscriptions flag, or in all subscriptions the token can access.
+func getAzureDiscoveredServices(flags *AzureFlags, token string) []AzureService {
+	services := []AzureService{}
+	subscriptionIds := []string{}
+	if strings.EqualFold(flags.Subscriptions, "all") {
+		for _, subscriptionId := range gjson.GetBytes(getAzureResource("https://management.azure.com/subscriptions?api-version=2020-01-01", token), "value.#.subscriptionId").Array() {
+			subscriptionIds = append(subscriptionIds, subscriptionId.String())
+		}
+	} else {
+		for _, subscriptionId := range strings.Split(flags.Subscriptions, ",") {
+			if strings.TrimSpace(subscriptionId) != "" {
+				subscriptionIds = append(subscriptionIds, strings.TrimSpace(subscriptionId))
+			}
+		}
+	}
+
+	for _, subscriptionId := range subscriptionIds {
+		nextLink := "https://management.azure.com/subscriptions/" + subscriptionId + "/providers/Microsoft.ApiManagement/service?api-version=2022-08-01"
+		for nextLink != "" {
+			var page struct {
+				Value    []AzureService `json:"value"`
+				NextLink string         `json:"nextLink"`
+			}
+			json.Unmarshal(getAzureResource(nextLink, token), &page)
+			services = append(services, page.Value...)
+			nextLink = page.NextLink
+		}
+	}
+
+	return services
+}
+
+// getAzureResource returns the body of an Azure resource, or nil and prints the status if it could not be read.
+func getAzureResource(resourceUrl string, token string) []byte {
+	body, status := getAzureResourceStatus(resourceUrl, token)
+	if status != 200 {
+		if status != 0 {
+			fmt.Println("  >> " + strconv.Itoa(status) + " " + http.StatusText(status) + " " + string(body))
+		}
+		return nil
+	}
+
+	return body
+}
+
+// getAzureResourceStatus returns the body and status code of an Azure resource, for callers that expect some statuses.
+func getAzureResourceStatus(resourceUrl string, token string) ([]byte, int) {
+	req, _ := http.NewRequest(http.MethodGet, resourceUrl, nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		return nil, 0
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println(err)
+		return nil, 0
+	}
+
+	return body, resp.StatusCode
+}
+
+// getAzureResourceScope returns the subscription and resource group of an Azure resource id.
+func getAzureResourceScope(resourceId string) (string, string) {
+	var subscriptionId, resourceGroup string
+	parts := strings.Split(resourceId, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if strings.EqualFold(parts[i], "subscriptions") && subscriptionId == "" {
+			subscriptionId = parts[i+1]
+		} else if strings.EqualFold(parts[i], "resourceGroups") && resourceGroup == "" {
+			resourceGroup = parts[i+1]
+		}
+	}
+
+	return subscriptionId, resourceGroup
+}
+
+func getAzureServiceUrl(subscriptionId string, resourceGroup string, serviceName string) string {
+	return "https://management.azure.com/subscriptions/" + subscriptionId + "/resourceGroups/" + resourceGroup + "/providers/Microsoft.ApiManagement/service/" + serviceName
+}
+
+// getAzurePolicyXml returns the raw policy XML of a service, product, API or operation, or an empty string if none is set.
+func getAzurePolicyXml(resourceUrl string, token string) string {
+	body, status := getAzureResourceStatus(resourceUrl+"/policies/policy?format=rawxml&api-version=2022-08-01", token)
+	if status == 404 {
+		// no policy is set
+		return ""
+	} else if status != 200 {
+		if status != 0 {
+			fmt.Println("  >> " + strconv.Itoa(status) + " " + http.StatusText(status) + " " + string(body))
+		}
+		return ""
+	}
+
+	return gjson.GetBytes(body, "properties.value").String()
+}
+
+// getAzureApiGroupName returns the name that all versions of an API are grouped under,
+// the version set if the API has one, otherwise the API name without a -v1 style suffix.
+func getAzureApiGroupName(api AzureApi) string {
+	if api.Properties.ApiVersionSet != nil && getAzureSlug(api.Properties.ApiVersionSet.Name) != "" {
+		// the group name is a directory and an API Hub id, so the display name is slugged
+		return getAzureSlug(api.Properties.ApiVersionSet.Name)
+	}
+
+	var re = regexp.MustCompile(`(-v\d+)$`)
+	return re.ReplaceAllString(api.Name, "")
+}
+
+// getAzureOperationsSpec synthesizes an OpenAPI 3 document from the operations of an API.
+func getAzureOperationsSpec(api AzureApi, operations AzureApiOperations) []byte {
+	version := api.Properties.ApiVersion
+	if version == "" {
+		version = "1.0"
+	}
+
+	paths := map[string]map[string]any{}
+	for _, operation := range operations.Value {
+		path, _, _ := strings.Cut(operation.Properties.UrlTemplate, "?")
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		if _, ok := paths[path]; !ok {
+			paths[path] = map[string]any{}
+		}
+
+		parameters := []map[string]any{}
+		for _, parameter := range operation.Properties.TemplateParameters {
+			parameters = append(parameters, getAzureSpecParameter(parameter, "path"))
+		}
+		for _, parameter := range operation.Properties.Request.QueryParameters {
+			parameters = append(parameters, getAzureSpecParameter(parameter, "query"))
+		}
+		for _, parameter := range operation.Properties.Request.Headers {
+			parameters = append(parameters, getAzureSpecParameter(parameter, "header"))
+		}
+
+		responses := map[string]any{}
+		for _, response := range operation.Properties.Responses {
+			specResponse := map[string]any{"description": response.Description}
+			if response.Description == "" {
+				specResponse["description"] = http.StatusText(response.StatusCode)
+			}
+			if content := getAzureSpecContent(response.Representations); len(content) > 0 {
+				specResponse["content"] = content
+			}
+			responses[strconv.Itoa(response.StatusCode)] = specResponse
+		}
+		if len(responses) == 0 {
+			responses["default"] = map[string]any{"description": "Default response"}
+		}
+
+		specOperation := map[string]any{
+			"operationId": operation.Name,
+			"summary":     operation.Properties.DisplayName,
+			"responses":   responses,
+		}
+		if operation.Properties.Description != "" {
+			specOperation["description"] = operation.Properties.Description
+		}
+		if len(parameters) > 0 {
+			specOperation["parameters"] = parameters
+		}
+		if content := getAzureSpecContent(operation.Properties.Request.Representations); len(content) > 0 {
+			specOperation["requestBody"] = map[string]any{"description": operation.Properties.Request.Description, "content": content}
+		}
+
+		paths[path][strings.ToLower(operation.Properties.Method)] = specOperation
+	}
+
+	spec := map[string]any{
+		"openapi": "3.0.1",
+		"info": map[string]any{
+			"title":       api.Properties.DisplayName,
+			"description": api.Properties.Description,
+			"version":     version,
+		},
+		"servers": []map[string]any{{"url": "/" + api.Properties.Path}},
+		"paths":   paths,
+	}
+
+	bytes, _ := json.MarshalIndent(spec, "", "  ")
+	return bytes
+}
+
+func getAzureSpecParameter(parameter AzureParameter, in string) map[string]any {
+	schema := map[string]any{"type": "string"}
+	if parameter.Type != "" {
+		schema["type"] = parameter.Type
+	}
+	if parameter.DefaultValue != "" {
+		schema["default"] = parameter.DefaultValue
+	}
+	if len(parameter.Values) > 0 {
+		schema["enum"] = parameter.Values
+	}
+
+	specParameter := map[string]any{
+		"name":     parameter.Name,
+		"in":       in,
+		"required": parameter.Required || in == "path",
+		"schema":   schema,
+	}
+	if parameter.Description != "" {
+		specParameter["description"] = parameter.Description
+	}
+
+	return specParameter
+}
+
+func getAzureSpecContent(representations []AzureRepresentation) map[string]any {
+	content := map[string]any{}
+	for _, representation := range representations {
+		if representation.ContentType != "" {
+			content[representation.ContentType] = map[string]any{}
+		}
+	}
+
+	return content
+}
+
+// getAzureApiGraphqlSchema returns the SDL schema of a GraphQL API.
+func getAzureApiGraphqlSchema(subscriptionId string, resourceGroup string, serviceName string, apiName string, token string) string {
+	var schema string
+	body := getAzureResource(getAzureServiceUrl(subscriptionId, resourceGroup, serviceName)+"/apis/"+apiName+"/schemas?api-version=2022-08-01", token)
+	for _, value := range gjson.GetBytes(body, "value").Array() {
+		if strings.Contains(value.Get("properties.contentType").String(), "graphql") {
+			schema = value.Get("properties.document.value").String()
+		}
+	}
+
+	return schema
+}
+
+// getAzureWebSocketSpec describes a websocket API as an AsyncAPI document.
+func getAzureWebSocketSpec(api AzureApi) []byte {
+	version := api.Properties.ApiVersion
+	if version == "" {
+		version = "1.0"
+	}
+
+	spec := map[string]any{
+		"asyncapi": "2.6.0",
+		"info": map[string]any{
+			"title":       api.Properties.DisplayName,
+			"description": api.Properties.Description,
+			"version":     version,
+		},
+		"channels": map[string]any{
+			"/" + api.Properties.Path: map[string]any{
+				"publish":   map[string]any{"operationId": "send", "message": map[string]any{"payload": map[string]any{}}},
+				"subscribe": map[string]any{"operationId": "receive", "message": map[string]any{"payload": map[string]any{}}},
+			},
+		},
+	}
+	if api.Properties.ServiceUrl != "" {
+		spec["x-backend-url"] = api.Properties.ServiceUrl
+	}
+
+	bytes, _ := json.MarshalIndent(spec, "", "  ")
+	return bytes
+}
+
+// getAzureApiExport exports the spec of an API, trying each export format until one succeeds.
+func getAzureApiExport(subscriptionId string, resourceGroup string, serviceName string, apiName string, formats []string, token string) ([]byte, string) {
+	for _, format := range formats {
+		req, _ := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/"+subscriptionId+"/resourceGroups/"+resourceGroup+"/providers/Microsoft.ApiManagement/service/"+serviceName+"/apis/"+apiName+"?format="+url.QueryEscape(format)+"&export=true&api-version=2022-08-01", nil)
+		req.Header.Add("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != 200 {
+			continue
+		}
+
+		// link formats return a download url for the document
+		link := gjson.GetBytes(body, "value.link").String()
+		if link != "" {
+			linkResp, err := http.Get(link)
+			if err != nil {
+				continue
+			}
+			document, err := io.ReadAll(linkResp.Body)
+			linkResp.Body.Close()
+			if err == nil && linkResp.StatusCode == 200 && len(document) > 0 {
+				return document, format
+			}
+			continue
+		}
+
+		value := gjson.GetBytes(body, "value")
+		if value.IsObject() {
+			return []byte(value.Raw), format
+		} else if value.Type == gjson.String && value.String() != "" {
+			return []byte(value.String()), format
+		}
+	}
+
+	return nil, ""
+}
+
+func getAzureApiRevisions(subscriptionId string, resourceGroup string, serviceName string, apiName string, token string) AzureApiRevisions {
+	var revisions AzureApiRevisions
+	json.Unmarshal(getAzureResource(getAzureServiceUrl(subscriptionId, resourceGroup, serviceName)+"/apis/"+apiName+"/revisions?api-version=2022-08-01", token), &revisions)
+
+	return revisions
+}
+
+func isAzureApiFile(fileName string) bool {
+	for _, suffix := range azureSupportFileSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return false
+		}
+	}
+
+	return strings.HasSuffix(fileName, ".json")
+}
+
+func azureOfframp(flags *AzureFlags) error {
+
+	azureBaseDir := "src/main/azure"
+
+	// discovered services are stored per subscription and resource group
+	stores, _ := filepath.Glob("src/main/azure/subscriptions/*/*/*")
+
+	_, err := os.ReadDir(azureBaseDir + "/apiproxies")
+	if err != nil && len(stores) == 0 {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Offramping Azure API Management APIs to general...")
+
+	if err == nil {
+		azureOfframpApis(flags, azureBaseDir, "")
+	}
+
+	for _, store := range stores {
+		s := strings.Split(filepath.ToSlash(store), "/")
+		storeFlags := *flags
+		storeFlags.Subscription = s[len(s)-3]
+		storeFlags.ResourceGroup = s[len(s)-2]
+		storeFlags.ServiceName = s[len(s)-1]
+		azureOfframpApis(&storeFlags, store, storeFlags.ServiceName)
+	}
+
+	return nil
+}
+
+func azureOfframpApis(flags *AzureFlags, azureDir string, namespace string) {
+	azureBaseDir := azureDir + "/apiproxies"
+	baseDir := "src/main/general/apiproxies"
+
+	if flags.Subscription == "" {
+		fmt.Println("No subscription given, cannot offramp Azure APIs.")
+		return
+	} else if flags.ResourceGroup == "" {
+		fmt.Println("No resource group given, cannot offramp Azure APIs.")
+		return
+	} else if flags.ServiceName == "" {
+		fmt.Println("No service name given, cannot offramp Azure APIs.")
+		return
+	}
+
+	entries, _ := os.ReadDir(azureBaseDir)
+
+	// load azureService info, if available
+	var azureService AzureService
+	azureServiceFile, err := os.Open(azureDir + "/" + flags.ServiceName + ".json")
+
+	if err == nil {
 		byteValue, _ := io.ReadAll(azureServiceFile)
 		json.Unmarshal(byteValue, &azureService)
 	}
 
+	// load self-hosted gateways, if available
+	var azureGateways []AzureGateway
+	gatewayEntries, _ := os.ReadDir(azureDir + "/gateways")
+	for _, g := range gatewayEntries {
+		var azureGateway AzureGateway
+		byteValue, err := os.ReadFile(azureDir + "/gateways/" + g.Name())
+		if err == nil {
+			json.Unmarshal(byteValue, &azureGateway)
+			azureGateways = append(azureGateways, azureGateway)
+		}
+	}
+
 	for _, e := range entries {
 		if flags.ApiName == "" || flags.ApiName == e.Name() {
 			fmt.Println(e.Name())
@@ -444,7 +1420,7 @@ func azureOfframp(flags *AzureFlags) error {
 			// read all files
 			fileEntries, _ := os.ReadDir(azureBaseDir + "/" + e.Name())
 			for _, f := range fileEntries {
-				if !strings.HasSuffix(f.Name(), "-oas.json") && !strings.HasSuffix(f.Name(), "-oas-definition.json") {
+				if isAzureApiFile(f.Name()) {
 					// this is an API file
 					var azureApi AzureApi
 					apiFile, err := os.Open(azureBaseDir + "/" + e.Name() + "/" + f.Name())
@@ -458,39 +1434,368 @@ func azureOfframp(flags *AzureFlags) error {
 					defer apiFile.Close()
 
 					if azureApi.Name != "" {
+						// discovered services are namespaced, so the same API in several services are deployments of one version
+						namePrefix := azureApi.Name
 						var generalApi GeneralApi
-						generalApi.Name = azureApi.Name + "-azure"
+						if namespace != "" {
+							namePrefix = azureApi.Name + "-" + namespace
+							generalApi.VersionName = azureApi.Name
+						}
+						generalApi.Name = namePrefix + "-azure"
 						generalApi.DisplayName = azureApi.Properties.DisplayName
 						generalApi.Description = azureApi.Properties.Description
 						generalApi.Version = azureApi.Properties.ApiVersion
 						generalApi.OwnerEmail = azureService.Properties.PublisherEmail
 						generalApi.OwnerName = azureService.Properties.PublisherName
 						generalApi.DocumentationUrl = azureService.Properties.DeveloperPortalUrl + "/api-details#api=" + azureApi.Name
-						generalApi.GatewayUrl = azureService.Properties.GatewayUrl + "/" + azureApi.Properties.Path
+						apiPath := "/" + azureApi.Properties.Path
+						if versionSet := azureApi.Properties.ApiVersionSet; versionSet != nil {
+							generalApi.VersioningScheme = versionSet.VersioningScheme
+							if versionSet.VersioningScheme == "Header" {
+								generalApi.VersionParameter = versionSet.VersionHeaderName
+							} else if versionSet.VersioningScheme == "Query" {
+								generalApi.VersionParameter = versionSet.VersionQueryName
+							} else if versionSet.VersioningScheme == "Segment" && azureApi.Properties.ApiVersion != "" {
+								// segment versions are part of the url
+								apiPath = apiPath + "/" + azureApi.Properties.ApiVersion
+							}
+						}
+						generalApi.GatewayUrl = azureService.Properties.GatewayUrl + apiPath
+						generalApi.Region = azureService.Location
 						generalApi.BasePath = azureApi.Properties.Path
+						if azureApi.Properties.ServiceUrl != "" {
+							generalApi.Backends = []GeneralBackend{{Name: "default", Type: "http", Url: azureApi.Properties.ServiceUrl}}
+						}
+						if azureApi.Properties.Type == "soap" || azureApi.Properties.Type == "graphql" || azureApi.Properties.Type == "websocket" {
+							generalApi.Protocol = azureApi.Properties.Type
+						}
+						if azureApi.Properties.Type == "websocket" {
+							generalApi.GatewayUrl = strings.Replace(generalApi.GatewayUrl, "https://", "wss://", 1)
+						}
 						generalApi.PlatformId = "azure-api-management"
 						generalApi.PlatformName = "Azure API Management"
 						generalApi.PlatformResourceUri = "https://portal.azure.com/#resource/subscriptions/" + flags.Subscription + "/resourceGroups/" + flags.ResourceGroup + "/providers/Microsoft.ApiManagement/service/" + flags.ServiceName + "/overview?apiName=" + azureApi.Name
 
+						var revisions AzureApiRevisions
+						revisionBytes, err := os.ReadFile(azureBaseDir + "/" + e.Name() + "/" + azureApi.Name + "-revisions.json")
+						if err == nil {
+							json.Unmarshal(revisionBytes, &revisions)
+							for _, revision := range revisions.Value {
+								generalApi.Revisions = append(generalApi.Revisions, GeneralRevision{Revision: revision.ApiRevision, Description: revision.Description, IsCurrent: revision.IsCurrent, CreatedDate: revision.CreatedDateTime, UpdatedDate: revision.UpdatedDateTime})
+							}
+						}
+
 						bytes, _ := json.MarshalIndent(generalApi, "", "  ")
 						//os.RemoveAll(baseDir + "/" + generalApi.Name)
 						os.MkdirAll(baseDir+"/"+e.Name(), 0755)
 
 						//os.WriteFile(baseDir+"/"+e.Name()+"/"+e.Name()+".json", bytes, 0644)
-						writeGeneralApi(e.Name(), generalApi)
+						if azureApi.Properties.ApiVersionSet != nil && azureApi.Properties.ApiVersionSet.Name != "" {
+							// the canonical API is named after its version set
+							canonicalApi := generalApi
+							canonicalApi.DisplayName = azureApi.Properties.ApiVersionSet.Name
+							if azureApi.Properties.ApiVersionSet.Description != "" {
+								canonicalApi.Description = azureApi.Properties.ApiVersionSet.Description
+							}
+							writeGeneralApi(e.Name(), canonicalApi)
+						} else {
+							writeGeneralApi(e.Name(), generalApi)
+						}
 						os.WriteFile(baseDir+"/"+e.Name()+"/"+generalApi.Name+".json", bytes, 0644)
 
-						schemaFile, err := os.Open(azureBaseDir + "/" + e.Name() + "/" + azureApi.Name + "-oas.json")
-						if err == nil {
-							// we have an api spec, copy it over
-							byteValue, _ := io.ReadAll(schemaFile)
-							os.WriteFile(baseDir+"/"+e.Name()+"/"+generalApi.Name+"-oas.json", byteValue, 0644)
+						// every other location the API is reachable from is a deployment of the same version
+						for _, deployment := range getAzureApiDeployments(generalApi, azureApi, azureService, azureGateways, namePrefix, apiPath) {
+							bytes, _ := json.MarshalIndent(deployment, "", "  ")
+							os.WriteFile(baseDir+"/"+e.Name()+"/"+deployment.Name+".json", bytes, 0644)
+						}
+
+						for _, format := range generalSpecFormats {
+							byteValue, err := os.ReadFile(azureBaseDir + "/" + e.Name() + "/" + azureApi.Name + format.Suffix)
+							if err == nil {
+								// we have an api spec, copy it over
+								os.WriteFile(baseDir+"/"+e.Name()+"/"+generalApi.Name+format.Suffix, byteValue, 0644)
+							}
 						}
 					}
 				}
 			}
 		}
 	}
+}
+
+// getAzureApiDeployments returns a deployment for each additional region, custom hostname and self-hosted gateway an API is reachable from.
+func getAzureApiDeployments(generalApi GeneralApi, azureApi AzureApi, azureService AzureService, azureGateways []AzureGateway, namePrefix string, apiPath string) []GeneralApi {
+	deployments := []GeneralApi{}
+	newDeployment := func(id string) GeneralApi {
+		deployment := generalApi
+		deployment.Name = namePrefix + "-" + getAzureSlug(id) + "-azure"
+		deployment.VersionName = azureApi.Name
+		deployment.Revisions = nil
+		return deployment
+	}
+
+	for _, location := range azureService.Properties.AdditionalLocations {
+		if !location.DisableGateway && location.GatewayRegionalUrl != "" {
+			deployment := newDeployment(location.Location)
+			deployment.GatewayUrl = location.GatewayRegionalUrl + apiPath
+			deployment.Region = location.Location
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	for _, hostname := range azureService.Properties.HostnameConfigurations {
+		if hostname.Type == "Proxy" && !strings.HasSuffix(hostname.HostName, ".azure-api.net") {
+			deployment := newDeployment(hostname.HostName)
+			deployment.GatewayUrl = "https://" + hostname.HostName + apiPath
+			deployment.DisplayName = generalApi.DisplayName + " " + hostname.HostName
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	// gateways list APIs by their Azure name, before any version suffix was added
+	azureApiName := azureApi.Name
+	if index := strings.LastIndex(azureApi.Id, "/"); index >= 0 {
+		azureApiName = azureApi.Id[index+1:]
+	}
+	for _, gateway := range azureGateways {
+		if slices.Contains(gateway.Apis, azureApiName) {
+			deployment := newDeployment(gateway.Name)
+			deployment.GatewayUrl = ""
+			if len(gateway.Hostnames) > 0 {
+				deployment.GatewayUrl = "https://" + gateway.Hostnames[0] + apiPath
+			}
+			deployment.Region = gateway.Properties.LocationData.Name
+			deployment.Gateway = gateway.Name
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	if generalApi.Protocol == "websocket" {
+		for i := range deployments {
+			deployments[i].GatewayUrl = strings.Replace(deployments[i].GatewayUrl, "https://", "wss://", 1)
+		}
+	}
+
+	return deployments
+}
+
+func getAzureSlug(value string) string {
+	var re = regexp.MustCompile(`[^a-z0-9]+`)
+	return strings.Trim(re.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+func azureOnramp(flags *AzureFlags) error {
+	generalBaseDir := "src/main/general/apiproxies"
+	baseDir := "src/main/azure/onramp/apiproxies"
+
+	entries, err := os.ReadDir(generalBaseDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Onramping general APIs to Azure API Management...")
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		var generalApi GeneralApi
+		byteValue, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + e.Name() + ".json")
+		if err == nil {
+			json.Unmarshal(byteValue, &generalApi)
+		}
+
+		// paths must be unique per version in a service
+		usedPaths := make(map[string]bool)
+		versioned := false
+
+		fileEntries, _ := os.ReadDir(generalBaseDir + "/" + e.Name())
+		for _, f := range fileEntries {
+			if !isGeneralDeploymentFile(f.Name()) {
+				continue
+			}
+
+			var generalDeploymentApi GeneralApi
+			byteValue, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + f.Name())
+			if err == nil {
+				json.Unmarshal(byteValue, &generalDeploymentApi)
+			}
+			if generalDeploymentApi.Name == "" {
+				continue
+			} else if generalDeploymentApi.PlatformId == "azure-api-management" {
+				// already in Azure API Management
+				continue
+			} else if generalDeploymentApi.Protocol != "" {
+				fmt.Println("  >> Skipping " + generalDeploymentApi.Name + ", " + generalDeploymentApi.Protocol + " APIs are not onramped to Azure.")
+				continue
+			}
+
+			fmt.Println(generalDeploymentApi.Name)
+
+			path := strings.Trim(generalDeploymentApi.BasePath, "/")
+			if path == "" {
+				path = e.Name()
+			}
+			if usedPaths[path+"@"+generalDeploymentApi.Version] {
+				path = path + "/" + getAzureSlug(generalDeploymentApi.Name)
+			}
+			usedPaths[path+"@"+generalDeploymentApi.Version] = true
+
+			var azureApi AzureApiImport
+			azureApi.Properties.DisplayName = generalDeploymentApi.DisplayName
+			azureApi.Properties.Description = generalDeploymentApi.Description
+			azureApi.Properties.Path = path
+			// proxy to the first http backend, or else to the current gateway
+			azureApi.Properties.ServiceUrl = generalDeploymentApi.GatewayUrl
+			for _, backend := range generalDeploymentApi.Backends {
+				if backend.Type == "http" && strings.HasPrefix(backend.Url, "http") {
+					azureApi.Properties.ServiceUrl = backend.Url
+					break
+				}
+			}
+			azureApi.Properties.Protocols = []string{"https"}
+			azureApi.Properties.SubscriptionRequired = true
+			azureApi.Properties.ApiVersion = generalDeploymentApi.Version
+			if generalDeploymentApi.Version != "" {
+				versioned = true
+			}
+
+			bytes, _ := json.MarshalIndent(azureApi, "", "  ")
+			os.MkdirAll(baseDir+"/"+e.Name(), 0755)
+			os.WriteFile(baseDir+"/"+e.Name()+"/"+generalDeploymentApi.Name+".json", bytes, 0644)
+
+			spec, err := os.ReadFile(generalBaseDir + "/" + e.Name() + "/" + generalDeploymentApi.Name + "-oas.json")
+			if err == nil {
+				os.WriteFile(baseDir+"/"+e.Name()+"/"+generalDeploymentApi.Name+"-oas.json", spec, 0644)
+			}
+		}
+
+		if versioned {
+			// versions of an API are grouped in a version set named after the general API
+			var versionSet AzureApiVersionSetImport
+			versionSet.Properties.DisplayName = generalApi.DisplayName
+			if versionSet.Properties.DisplayName == "" {
+				versionSet.Properties.DisplayName = e.Name()
+			}
+			versionSet.Properties.Description = generalApi.Description
+			versionSet.Properties.VersioningScheme = "Segment"
+			if generalApi.VersioningScheme == "Header" {
+				versionSet.Properties.VersioningScheme = "Header"
+				versionSet.Properties.VersionHeaderName = generalApi.VersionParameter
+			} else if generalApi.VersioningScheme == "Query" {
+				versionSet.Properties.VersioningScheme = "Query"
+				versionSet.Properties.VersionQueryName = generalApi.VersionParameter
+			}
+
+			bytes, _ := json.MarshalIndent(versionSet, "", "  ")
+			os.WriteFile(baseDir+"/"+e.Name()+"/versionset.json", bytes, 0644)
+		}
+	}
 
 	return nil
 }
+
+func azureImport(flags *AzureFlags) error {
+	baseDir := "src/main/azure/onramp/apiproxies"
+	if flags.Subscription == "" {
+		fmt.Println("No subscription given, cannot import Azure APIs.")
+		return nil
+	} else if flags.ResourceGroup == "" {
+		fmt.Println("No resource group given, cannot import Azure APIs.")
+		return nil
+	} else if flags.ServiceName == "" {
+		fmt.Println("No service name given, cannot import Azure APIs.")
+		return nil
+	}
+
+	token := getAzureAccessToken(flags)
+	if token == "" {
+		fmt.Println("Could not get valid Azure token, cannot import Azure APIs.")
+		return nil
+	}
+
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		fmt.Println("No onramped APIs found, run azure apis onramp first.")
+		return nil
+	}
+
+	serviceUrl := getAzureServiceUrl(flags.Subscription, flags.ResourceGroup, flags.ServiceName)
+	serviceId := strings.TrimPrefix(serviceUrl, "https://management.azure.com")
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		versionSetId := ""
+		versionSet, err := os.ReadFile(baseDir + "/" + e.Name() + "/versionset.json")
+		if err == nil {
+			fmt.Println("Creating API version set " + e.Name() + "...")
+			if putAzureResource(serviceUrl+"/apiVersionSets/"+e.Name()+"?api-version=2022-08-01", versionSet, token) {
+				versionSetId = serviceId + "/apiVersionSets/" + e.Name()
+			}
+		}
+
+		fileEntries, _ := os.ReadDir(baseDir + "/" + e.Name())
+		for _, f := range fileEntries {
+			if f.Name() == "versionset.json" || strings.HasSuffix(f.Name(), "-oas.json") {
+				continue
+			}
+
+			var azureApi AzureApiImport
+			byteValue, err := os.ReadFile(baseDir + "/" + e.Name() + "/" + f.Name())
+			if err != nil {
+				continue
+			}
+			json.Unmarshal(byteValue, &azureApi)
+
+			apiName := strings.TrimSuffix(f.Name(), ".json")
+			if azureApi.Properties.ApiVersion != "" {
+				azureApi.Properties.ApiVersionSetId = versionSetId
+			}
+
+			spec, err := os.ReadFile(baseDir + "/" + e.Name() + "/" + apiName + "-oas.json")
+			if err == nil {
+				// import the spec to create the operations
+				azureApi.Properties.Format = "openapi+json"
+				if gjson.GetBytes(spec, "swagger").Exists() {
+					azureApi.Properties.Format = "swagger-json"
+				}
+				azureApi.Properties.Value = string(spec)
+			}
+
+			fmt.Println("Creating API " + apiName + "...")
+			bytes, _ := json.Marshal(azureApi)
+			putAzureResource(serviceUrl+"/apis/"+apiName+"?api-version=2022-08-01", bytes, token)
+		}
+	}
+
+	return nil
+}
+
+// putAzureResource creates or updates an Azure resource, and returns if it was successful.
+func putAzureResource(resourceUrl string, body []byte, token string) bool {
+	return sendAzureResource(http.MethodPut, resourceUrl, body, token)
+}
+
+func sendAzureResource(method string, resourceUrl string, body []byte, token string) bool {
+	req, _ := http.NewRequest(method, resourceUrl, bytes.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		fmt.Println("  >> " + resp.Status + " " + string(responseBody))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AzurePolicyNode is a generic element of an Azure policy document.
+type AzurePolicyNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr        `xml:",any,attr"`
+	Nodes   []AzurePolicyNode `xml:",any"`
+	Text    string            `xml:",chardata"`
+}
+
+type AzurePolicyReport struct {
+	Api        string                   `json:"api"`
+	Translated int                      `json:"translated"`
+	Partial    int                      `json:"partial"`
+	Manual     int                      `json:"manual"`
+	Policies   []AzurePolicyTranslation `json:"policies"`
+}
+
+type AzurePolicyTranslation struct {
+	Scope        string   `json:"scope"`
+	Section      string   `json:"section"`
+	AzurePolicy  string   `json:"azurePolicy"`
+	ApigeePolicy string   `json:"apigeePolicy,omitempty"`
+	Attach       string   `json:"attach,omitempty"`
+	Status       string   `json:"status"`
+	Notes        []string `json:"notes,omitempty"`
+}
+
+// hints for Azure policies that are not translated automatically
+var azurePolicyHints = map[string]string{
+	"cache-lookup":                    "Use an Apigee ResponseCache policy.",
+	"cache-store":                     "Use an Apigee ResponseCache policy.",
+	"check-header":                    "Use a RaiseFault policy with a condition on the header.",
+	"choose":                          "Use conditional steps in the proxy flows.",
+	"json-to-xml":                     "Use an Apigee JSONToXML policy.",
+	"xml-to-json":                     "Use an Apigee XMLToJSON policy.",
+	"log-to-eventhub":                 "Use an Apigee MessageLogging policy.",
+	"mock-response":                   "Use an AssignMessage policy on a route rule without a target.",
+	"return-response":                 "Use a RaiseFault or AssignMessage policy.",
+	"send-request":                    "Use an Apigee ServiceCallout policy.",
+	"set-variable":                    "Use an AssignMessage policy with AssignVariable.",
+	"validate-content":                "Use an Apigee OASValidation policy.",
+	"validate-parameters":             "Use an Apigee OASValidation policy.",
+	"authentication-managed-identity": "Use Google authentication on the target endpoint.",
+}
+
+func (node AzurePolicyNode) attr(name string) string {
+	for _, attr := range node.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+func (node AzurePolicyNode) children(name string) []AzurePolicyNode {
+	result := []AzurePolicyNode{}
+	for _, child := range node.Nodes {
+		if child.XMLName.Local == name {
+			result = append(result, child)
+		}
+	}
+
+	return result
+}
+
+func (node AzurePolicyNode) childTexts(name string, childName string) []string {
+	result := []string{}
+	for _, child := range node.children(name) {
+		for _, value := range child.children(childName) {
+			result = append(result, strings.TrimSpace(value.Text))
+		}
+	}
+
+	return result
+}
+
+func azurePoliciesTranslate(flags *AzureFlags) error {
+	azureBaseDir := "src/main/azure"
+
+	// discovered services are stored per subscription and resource group
+	stores, _ := filepath.Glob("src/main/azure/subscriptions/*/*/*")
+
+	_, err := os.ReadDir(azureBaseDir + "/apiproxies")
+	if err != nil && len(stores) == 0 {
+		fmt.Println("No exported Azure APIs found, nothing to translate.")
+		return nil
+	}
+
+	fmt.Println("Translating Azure API Management policies to Apigee...")
+
+	translateAzureStorePolicies(flags, azureBaseDir, "")
+	for _, store := range stores {
+		translateAzureStorePolicies(flags, store, filepath.Base(store))
+	}
+
+	return nil
+}
+
+// translateAzureStorePolicies translates the policies of the APIs of one exported service, reports and Apigee policies of
+// discovered services are named after the API and the service, like their general APIs.
+func translateAzureStorePolicies(flags *AzureFlags, azureBaseDir string, namespace string) {
+	apigeeBaseDir := "src/main/apigee/policies"
+	reportsDir := "src/main/azure/reports"
+
+	entries, _ := os.ReadDir(azureBaseDir + "/apiproxies")
+
+	var products []AzureProduct
+	productEntries, _ := os.ReadDir(azureBaseDir + "/products")
+	for _, p := range productEntries {
+		if strings.HasSuffix(p.Name(), ".json") {
+			var product AzureProduct
+			byteValue, err := os.ReadFile(azureBaseDir + "/products/" + p.Name())
+			if err == nil {
+				json.Unmarshal(byteValue, &product)
+				products = append(products, product)
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if flags.ApiName != "" && flags.ApiName != e.Name() {
+			continue
+		}
+
+		fileEntries, _ := os.ReadDir(azureBaseDir + "/apiproxies/" + e.Name())
+		for _, f := range fileEntries {
+			if !isAzureApiFile(f.Name()) {
+				continue
+			}
+
+			var azureApi AzureApi
+			byteValue, err := os.ReadFile(azureBaseDir + "/apiproxies/" + e.Name() + "/" + f.Name())
+			if err == nil {
+				json.Unmarshal(byteValue, &azureApi)
+			}
+			if azureApi.Name == "" {
+				continue
+			}
+
+			fmt.Println(azureApi.Name)
+			outputName := azureApi.Name
+			if namespace != "" {
+				outputName = azureApi.Name + "-" + namespace
+			}
+
+			// policies are applied global first, then product, API and operation
+			report := AzurePolicyReport{Api: azureApi.Name, Policies: []AzurePolicyTranslation{}}
+			apigeePolicies := make(map[string]string)
+			translateAzurePolicyFile(azureBaseDir+"/policies/global.xml", "global", &report, apigeePolicies)
+			for _, product := range products {
+				if slices.Contains(product.Apis, e.Name()) {
+					translateAzurePolicyFile(azureBaseDir+"/products/"+product.Name+"-policy.xml", "product:"+product.Name, &report, apigeePolicies)
+				}
+			}
+			apiPrefix := azureBaseDir + "/apiproxies/" + e.Name() + "/" + azureApi.Name + "-policy"
+			translateAzurePolicyFile(apiPrefix+".xml", "api", &report, apigeePolicies)
+			operationFiles, _ := filepath.Glob(apiPrefix + "-*.xml")
+			for _, operationFile := range operationFiles {
+				operationName := strings.TrimSuffix(strings.TrimPrefix(operationFile, apiPrefix+"-"), ".xml")
+				translateAzurePolicyFile(operationFile, "operation:"+operationName, &report, apigeePolicies)
+			}
+
+			if len(report.Policies) == 0 {
+				continue
+			}
+
+			os.RemoveAll(apigeeBaseDir + "/" + outputName)
+			if len(apigeePolicies) > 0 {
+				os.MkdirAll(apigeeBaseDir+"/"+outputName, 0755)
+				for name, policy := range apigeePolicies {
+					os.WriteFile(apigeeBaseDir+"/"+outputName+"/"+name+".xml", []byte(policy), 0644)
+				}
+			}
+
+			bytes, _ := json.MarshalIndent(report, "", "  ")
+			os.MkdirAll(reportsDir, 0755)
+			os.WriteFile(reportsDir+"/"+outputName+"-policies.json", bytes, 0644)
+
+			fmt.Println("  >> " + strconv.Itoa(report.Translated) + " translated, " + strconv.Itoa(report.Partial) + " partially translated, " + strconv.Itoa(report.Manual) + " need manual work.")
+		}
+	}
+}
+
+func translateAzurePolicyFile(fileName string, scope string, report *AzurePolicyReport, apigeePolicies map[string]string) {
+	byteValue, err := os.ReadFile(fileName)
+	if err != nil {
+		return
+	}
+
+	var document AzurePolicyNode
+	err = xml.Unmarshal(byteValue, &document)
+	if err != nil {
+		report.Policies = append(report.Policies, AzurePolicyTranslation{Scope: scope, AzurePolicy: "policies", Status: "manual", Notes: []string{"Policy XML could not be parsed: " + err.Error()}})
+		report.Manual++
+		return
+	}
+
+	for _, section := range document.Nodes {
+		for _, policy := range section.Nodes {
+			if policy.XMLName.Local == "base" {
+				// inherited policies are translated at their own scope
+				continue
+			}
+
+			name := fmt.Sprintf("%s-%d", policy.XMLName.Local, len(report.Policies)+1)
+			translation := translateAzurePolicy(policy, section.XMLName.Local, name)
+			translation.Scope = scope
+			translation.Section = section.XMLName.Local
+			translation.AzurePolicy = policy.XMLName.Local
+			if translation.Attach == "" && translation.Status != "manual" {
+				translation.Attach = getApigeePolicyAttachment(scope, section.XMLName.Local)
+			}
+
+			switch translation.Status {
+			case "translated":
+				report.Translated++
+			case "partial":
+				report.Partial++
+			default:
+				report.Manual++
+			}
+
+			report.Policies = append(report.Policies, translation.AzurePolicyTranslation)
+			if translation.ApigeePolicy != "" {
+				apigeePolicies[translation.ApigeePolicy] = translation.Xml
+			}
+		}
+	}
+}
+
+type azurePolicyResult struct {
+	AzurePolicyTranslation
+	Xml string
+}
+
+// translateAzurePolicy maps a single Azure policy to an equivalent Apigee policy, if there is one.
+func translateAzurePolicy(policy AzurePolicyNode, section string, name string) azurePolicyResult {
+	var result azurePolicyResult
+	result.Status = "translated"
+	// notes that describe a difference in behavior, but need no manual work
+	var info []string
+
+	switch policy.XMLName.Local {
+	case "rate-limit", "rate-limit-by-key":
+		calls, _ := strconv.Atoi(policy.attr("calls"))
+		period, _ := strconv.Atoi(policy.attr("renewal-period"))
+		if calls == 0 || period == 0 {
+			return manualAzurePolicy("The rate limit has no calls or renewal period.")
+		}
+
+		result.ApigeePolicy = "SA-" + name
+		rate := int(math.Ceil(float64(calls) * 60 / float64(period)))
+		identifier, note := getApigeeIdentifier(policy.attr("counter-key"))
+		if note != "" {
+			result.Notes = append(result.Notes, note)
+		} else if policy.attr("counter-key") == "" {
+			info = append(info, "Azure counts calls per subscription, Apigee counts them per app with the client_id of a verified key or token.")
+		}
+		info = append(info, "SpikeArrest smooths traffic to "+strconv.Itoa(rate)+" calls per minute instead of counting calls in a fixed window.")
+		result.Xml = getApigeePolicyXml("SpikeArrest", result.ApigeePolicy, identifier+"\n  <Rate>"+strconv.Itoa(rate)+"pm</Rate>")
+	case "quota", "quota-by-key":
+		calls, _ := strconv.Atoi(policy.attr("calls"))
+		period, _ := strconv.Atoi(policy.attr("renewal-period"))
+		if calls == 0 || period == 0 {
+			return manualAzurePolicy("Bandwidth quotas have no Apigee equivalent.")
+		}
+
+		result.ApigeePolicy = "Q-" + name
+		interval, timeUnit := getApigeeQuotaInterval(period)
+		identifier, note := getApigeeIdentifier(policy.attr("counter-key"))
+		if note != "" {
+			result.Notes = append(result.Notes, note)
+		} else if policy.attr("counter-key") == "" {
+			info = append(info, "Azure counts calls per subscription, Apigee counts them per app with the client_id of a verified key or token.")
+		}
+		if interval*getApigeeTimeUnitSeconds(timeUnit) != period {
+			result.Notes = append(result.Notes, "The renewal period of "+strconv.Itoa(period)+" seconds was rounded to "+strconv.Itoa(interval)+" "+timeUnit+".")
+		}
+		result.Xml = getApigeePolicyXml("Quota", result.ApigeePolicy, identifier+"\n  <Allow count=\""+strconv.Itoa(calls)+"\"/>\n  <Interval>"+strconv.Itoa(interval)+"</Interval>\n  <TimeUnit>"+timeUnit+"</TimeUnit>\n  <Distributed>true</Distributed>\n  <Synchronous>true</Synchronous>")
+	case "validate-jwt":
+		result.ApigeePolicy = "VJ-" + name
+		source := "request.header.authorization"
+		if header := policy.attr("header-name"); header != "" && !strings.EqualFold(header, "authorization") {
+			source = "request.header." + header
+		} else if query := policy.attr("query-parameter-name"); query != "" {
+			source = "request.queryparam." + query
+		}
+
+		body := "\n  <Source>" + source + "</Source>"
+		if openIdConfigs := policy.children("openid-config"); len(openIdConfigs) > 0 {
+			body = "\n  <Algorithm>RS256</Algorithm>" + body + "\n  <PublicKey>\n    <JWKS uri=\"" + escapeXml(openIdConfigs[0].attr("url")) + "\"/>\n  </PublicKey>"
+			result.Notes = append(result.Notes, "The JWKS uri is set to the OpenID configuration "+openIdConfigs[0].attr("url")+", replace it with its jwks_uri.")
+		} else if len(policy.children("issuer-signing-keys")) > 0 {
+			body = "\n  <Algorithm>HS256</Algorithm>" + body + "\n  <SecretKey>\n    <Value ref=\"private.jwt-signing-key\"/>\n  </SecretKey>"
+			result.Notes = append(result.Notes, "The issuer signing key must be stored in a key value map and loaded into private.jwt-signing-key.")
+		} else {
+			result.Notes = append(result.Notes, "No signing key or OpenID configuration is set, the key must be configured manually.")
+		}
+
+		issuers := policy.childTexts("issuers", "issuer")
+		if len(issuers) > 0 {
+			body = body + "\n  <Issuer>" + escapeXml(issuers[0]) + "</Issuer>"
+			if len(issuers) > 1 {
+				result.Notes = append(result.Notes, "Only the first issuer is verified, Apigee supports a single issuer.")
+			}
+		}
+		audiences := policy.childTexts("audiences", "audience")
+		if len(audiences) > 0 {
+			body = body + "\n  <Audience>" + escapeXml(strings.Join(audiences, ",")) + "</Audience>"
+		}
+		if len(policy.children("required-claims")) > 0 {
+			result.Notes = append(result.Notes, "Required claims must be added as AdditionalClaims.")
+		}
+
+		result.Xml = getApigeePolicyXml("VerifyJWT", result.ApigeePolicy, body)
+	case "set-backend-service":
+		baseUrl := policy.attr("base-url")
+		if baseUrl == "" {
+			return manualAzurePolicy("The backend entity " + policy.attr("backend-id") + " must be mapped to an Apigee target server.")
+		}
+
+		result.ApigeePolicy = "AM-" + name
+		result.Attach = "TargetEndpoint/PreFlow/Request"
+		result.Xml = getApigeePolicyXml("AssignMessage", result.ApigeePolicy, "\n  <AssignVariable>\n    <Name>target.url</Name>\n    <Value>"+escapeXml(baseUrl)+"</Value>\n  </AssignVariable>\n  <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>")
+	case "rewrite-uri":
+		result.ApigeePolicy = "AM-" + name
+		result.Attach = "TargetEndpoint/PreFlow/Request"
+		result.Status = "partial"
+		result.Notes = append(result.Notes, "The path suffix is no longer copied, the rewritten path "+policy.attr("template")+" must be appended to target.url.")
+		result.Xml = getApigeePolicyXml("AssignMessage", result.ApigeePolicy, "\n  <AssignVariable>\n    <Name>target.copy.pathsuffix</Name>\n    <Value>false</Value>\n  </AssignVariable>\n  <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>")
+	case "set-header", "set-query-parameter":
+		element, elementName := "Headers", "Header"
+		if policy.XMLName.Local == "set-query-parameter" {
+			element, elementName = "QueryParams", "QueryParam"
+		}
+		assignTo := "request"
+		if section == "outbound" || section == "on-error" {
+			assignTo = "response"
+		}
+
+		values := []string{}
+		for _, value := range policy.children("value") {
+			text := strings.TrimSpace(value.Text)
+			if strings.HasPrefix(text, "@") {
+				result.Notes = append(result.Notes, "The value expression "+text+" must be rewritten as an Apigee message template.")
+			}
+			values = append(values, escapeXml(text))
+		}
+
+		action := "Set"
+		switch policy.attr("exists-action") {
+		case "delete":
+			action = "Remove"
+		case "append":
+			action = "Add"
+		case "skip":
+			action = "Add"
+			result.Notes = append(result.Notes, "Existing values are not skipped, add a condition if the value must not be added.")
+		}
+
+		var item string
+		if action == "Remove" {
+			item = "<" + elementName + " name=\"" + escapeXml(policy.attr("name")) + "\"/>"
+		} else {
+			item = "<" + elementName + " name=\"" + escapeXml(policy.attr("name")) + "\">" + strings.Join(values, ",") + "</" + elementName + ">"
+		}
+
+		result.ApigeePolicy = "AM-" + name
+		result.Xml = getApigeePolicyXml("AssignMessage", result.ApigeePolicy, "\n  <"+action+">\n    <"+element+">\n      "+item+"\n    </"+element+">\n  </"+action+">\n  <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>\n  <AssignTo createNew=\"false\" transport=\"http\" type=\""+assignTo+"\"/>")
+	case "cors":
+		origins := policy.childTexts("allowed-origins", "origin")
+		methods := policy.childTexts("allowed-methods", "method")
+		headers := policy.childTexts("allowed-headers", "header")
+		exposeHeaders := policy.childTexts("expose-headers", "header")
+		maxAge := "1800"
+		for _, allowedMethods := range policy.children("allowed-methods") {
+			if value := allowedMethods.attr("preflight-result-max-age"); value != "" {
+				maxAge = value
+			}
+		}
+		allowCredentials := policy.attr("allow-credentials")
+		if allowCredentials == "" {
+			allowCredentials = "false"
+		}
+
+		result.ApigeePolicy = "CORS-" + name
+		result.Attach = "ProxyEndpoint/PreFlow/Request"
+		result.Xml = getApigeePolicyXml("CORS", result.ApigeePolicy, "\n  <AllowOrigins>"+escapeXml(strings.Join(origins, ", "))+"</AllowOrigins>\n  <AllowMethods>"+escapeXml(strings.Join(methods, ", "))+"</AllowMethods>\n  <AllowHeaders>"+escapeXml(strings.Join(headers, ", "))+"</AllowHeaders>\n  <ExposeHeaders>"+escapeXml(strings.Join(exposeHeaders, ", "))+"</ExposeHeaders>\n  <MaxAge>"+maxAge+"</MaxAge>\n  <AllowCredentials>"+allowCredentials+"</AllowCredentials>\n  <GeneratePreflightResponse>true</GeneratePreflightResponse>\n  <IgnoreUnresolvedVariables>true</IgnoreUnresolvedVariables>")
+	case "ip-filter":
+		noMatchAction, matchAction := "DENY", "ALLOW"
+		if policy.attr("action") == "forbid" {
+			noMatchAction, matchAction = "ALLOW", "DENY"
+		}
+
+		addresses := []string{}
+		for _, address := range policy.children("address") {
+			addresses = append(addresses, "\n      <SourceAddress mask=\"32\">"+escapeXml(strings.TrimSpace(address.Text))+"</SourceAddress>")
+		}
+		for _, addressRange := range policy.children("address-range") {
+			cidrs := getCidrsFromRange(addressRange.attr("from"), addressRange.attr("to"))
+			if len(cidrs) == 0 {
+				result.Notes = append(result.Notes, "The address range "+addressRange.attr("from")+" - "+addressRange.attr("to")+" could not be converted.")
+			}
+			for _, cidr := range cidrs {
+				address, mask, _ := strings.Cut(cidr, "/")
+				addresses = append(addresses, "\n      <SourceAddress mask=\""+mask+"\">"+address+"</SourceAddress>")
+			}
+		}
+
+		result.ApigeePolicy = "AC-" + name
+		result.Xml = getApigeePolicyXml("AccessControl", result.ApigeePolicy, "\n  <IPRules noRuleMatchAction=\""+noMatchAction+"\">\n    <MatchRule action=\""+matchAction+"\">"+strings.Join(addresses, "")+"\n    </MatchRule>\n  </IPRules>")
+	default:
+		hint, ok := azurePolicyHints[policy.XMLName.Local]
+		if !ok {
+			hint = "No Apigee equivalent is known, migrate this policy manually."
+		}
+		return manualAzurePolicy(hint)
+	}
+
+	if len(result.Notes) > 0 {
+		result.Status = "partial"
+	}
+	result.Notes = append(result.Notes, info...)
+
+	return result
+}
+
+func manualAzurePolicy(note string) azurePolicyResult {
+	var result azurePolicyResult
+	result.Status = "manual"
+	result.Notes = []string{note}
+	return result
+}
+
+// getApigeePolicyAttachment returns where in an Apigee proxy a policy from an Azure scope and section is attached.
+func getApigeePolicyAttachment(scope string, section string) string {
+	if section == "on-error" {
+		return "ProxyEndpoint/FaultRules"
+	} else if section == "backend" {
+		return "TargetEndpoint/PreFlow/Request"
+	}
+
+	flow := "ProxyEndpoint/PreFlow"
+	if operationName, found := strings.CutPrefix(scope, "operation:"); found {
+		flow = "ProxyEndpoint/Flows/" + operationName
+	}
+	if section == "outbound" {
+		return flow + "/Response"
+	}
+
+	return flow + "/Request"
+}
+
+func getApigeePolicyXml(policyType string, name string, body string) string {
+	return "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\n<" + policyType + " continueOnError=\"false\" enabled=\"true\" name=\"" + name + "\">\n  <DisplayName>" + name + "</DisplayName>" + body + "\n</" + policyType + ">\n"
+}
+
+// getApigeeIdentifier returns the Identifier of an Apigee policy counting per Azure counter key, and a note if the key could not be mapped.
+// Without a counter key Azure counts per subscription, which is the app in Apigee.
+func getApigeeIdentifier(key string) (string, string) {
+	if key == "" {
+		key = "@(context.Subscription.Id)"
+	}
+
+	variable, found := getApigeeVariable(key)
+	note := ""
+	if !found {
+		note = "The counter key " + key + " must be mapped to an Apigee flow variable, calls are counted per " + variable + " until then."
+	}
+
+	return "\n  <Identifier ref=\"" + variable + "\"/>", note
+}
+
+// getApigeeVariable maps simple Azure counter keys to Apigee flow variables, and falls back to a header for other keys.
+func getApigeeVariable(key string) (string, bool) {
+	switch key {
+	case "@(context.Request.IpAddress)":
+		return "client.ip", true
+	case "@(context.Subscription.Id)", "@(context.Subscription.Key)":
+		return "client_id", true
+	}
+
+	return "request.header.x-counter-key", false
+}
+
+func getApigeeQuotaInterval(seconds int) (int, string) {
+	for _, timeUnit := range []string{"month", "week", "day", "hour"} {
+		unitSeconds := getApigeeTimeUnitSeconds(timeUnit)
+		if seconds%unitSeconds == 0 {
+			return seconds / unitSeconds, timeUnit
+		}
+	}
+
+	return int(math.Max(1, math.Round(float64(seconds)/60))), "minute"
+}
+
+func getApigeeTimeUnitSeconds(timeUnit string) int {
+	switch timeUnit {
+	case "month":
+		return 2592000
+	case "week":
+		return 604800
+	case "day":
+		return 86400
+	case "hour":
+		return 3600
+	}
+
+	return 60
+}
+
+// getCidrsFromRange converts an inclusive IPv4 address range to CIDR blocks.
+func getCidrsFromRange(from string, to string) []string {
+	result := []string{}
+	fromIp := net.ParseIP(from).To4()
+	toIp := net.ParseIP(to).To4()
+	if fromIp == nil || toIp == nil {
+		return result
+	}
+
+	start := uint64(binary.BigEndian.Uint32(fromIp))
+	end := uint64(binary.BigEndian.Uint32(toIp))
+	for start <= end {
+		mask := 32
+		for mask > 0 {
+			size := uint64(1) << (32 - mask + 1)
+			if start%size != 0 || start+size-1 > end {
+				break
+			}
+			mask--
+		}
+
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, uint32(start))
+		result = append(result, ip.String()+"/"+strconv.Itoa(mask))
+		start = start + (uint64(1) << (32 - mask))
+	}
+
+	return result
+}
+
+func escapeXml(value string) string {
+	var buffer bytes.Buffer
+	xml.EscapeText(&buffer, []byte(value))
+	return buffer.String()
+}
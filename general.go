@@ -2,11 +2,35 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+// file suffixes of offramped general deployment files, one per platform
+var generalDeploymentSuffixes = []string{"-aws.json", "-azure.json", "-apicenter.json", "-apigee.json"}
+
+type GeneralSpecFormat struct {
+	Suffix      string
+	SpecType    string
+	DisplayName string
+	MimeType    string
+}
+
+// spec files that can be stored next to a general deployment, by file suffix
+var generalSpecFormats = []GeneralSpecFormat{
+	{Suffix: "-oas.json", SpecType: "openapi", DisplayName: "OpenAPI Spec", MimeType: "application/json"},
+	{Suffix: "-schema.graphql", SpecType: "graphql", DisplayName: "GraphQL Schema", MimeType: "text/plain"},
+	{Suffix: "-wsdl.xml", SpecType: "wsdl", DisplayName: "WSDL", MimeType: "application/xml"},
+	{Suffix: "-asyncapi.json", SpecType: "asyncapi", DisplayName: "AsyncAPI Spec", MimeType: "application/json"},
+}
+
 func generalCleanLocal(flags *GeneralFlags) error {
 	var baseDir = "src/main/general"
 	os.RemoveAll(baseDir)
@@ -17,6 +41,12 @@ func writeGeneralApi(name string, generalApi GeneralApi) error {
 	baseDir := "src/main/general/apiproxies"
 
 	generalApi.Name = name
+	// deployment specific fields don't apply to the API record
+	generalApi.VersionName = ""
+	generalApi.Region = ""
+	generalApi.AccountId = ""
+	generalApi.Gateway = ""
+	generalApi.Revisions = nil
 	var re = regexp.MustCompile(` v\d+`)
 	generalApi.DisplayName = re.ReplaceAllString(generalApi.DisplayName, "")
 
@@ -36,3 +66,113 @@ func writeGeneralApi(name string, generalApi GeneralApi) error {
 
 	return nil
 }
+
+func isGeneralDeploymentFile(fileName string) bool {
+	for _, suffix := range generalDeploymentSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getGeneralVersionName(fileName string) string {
+	for _, suffix := range generalDeploymentSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return strings.TrimSuffix(fileName, suffix)
+		}
+	}
+
+	return strings.TrimSuffix(fileName, ".json")
+}
+
+func writeGeneralProduct(generalProduct GeneralProduct) error {
+	baseDir := "src/main/general/products"
+
+	bytes, _ := json.MarshalIndent(generalProduct, "", "  ")
+	os.MkdirAll(baseDir, 0755)
+	return os.WriteFile(baseDir+"/"+generalProduct.Name+".json", bytes, 0644)
+}
+
+func getGeneralProducts() []GeneralProduct {
+	baseDir := "src/main/general/products"
+	products := []GeneralProduct{}
+
+	entries, _ := os.ReadDir(baseDir)
+	for _, e := range entries {
+		byteValue, err := os.ReadFile(baseDir + "/" + e.Name())
+		if err == nil {
+			var generalProduct GeneralProduct
+			json.Unmarshal(byteValue, &generalProduct)
+			if generalProduct.Name != "" {
+				products = append(products, generalProduct)
+			}
+		}
+	}
+
+	return products
+}
+
+// getGeneralChangelog describes the revision history of a deployment, newest revision first.
+func getGeneralChangelog(revisions []GeneralRevision) string {
+	sorted := slices.Clone(revisions)
+	slices.SortFunc(sorted, func(a GeneralRevision, b GeneralRevision) int {
+		ar, _ := strconv.Atoi(a.Revision)
+		br, _ := strconv.Atoi(b.Revision)
+		return br - ar
+	})
+
+	lines := []string{}
+	for _, revision := range sorted {
+		line := "- Revision " + revision.Revision
+		if revision.IsCurrent {
+			line += " (current)"
+		}
+		if revision.UpdatedDate != "" {
+			line += ", " + revision.UpdatedDate
+		}
+		if revision.Description != "" {
+			line += ": " + revision.Description
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// getGeneralJsonSpec returns a YAML OpenAPI spec as JSON, since general specs are stored and onramped as JSON.
+func getGeneralJsonSpec(spec []byte) ([]byte, error) {
+	if json.Valid(spec) {
+		return spec, nil
+	}
+
+	var document any
+	if err := yaml.Unmarshal(spec, &document); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(getGeneralJsonValue(document), "", "  ")
+}
+
+// getGeneralJsonValue converts YAML mappings with non string keys, like response codes, to JSON objects.
+func getGeneralJsonValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = getGeneralJsonValue(item)
+		}
+	case map[any]any:
+		object := map[string]any{}
+		for key, item := range value {
+			object[fmt.Sprint(key)] = getGeneralJsonValue(item)
+		}
+		return object
+	case []any:
+		for i, item := range value {
+			value[i] = getGeneralJsonValue(item)
+		}
+	}
+
+	return value
+}
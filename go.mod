@@ -11,7 +11,10 @@ require (
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.17 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.17 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/ini v1.8.1 // indirect
+	github.com/aws/aws-sdk-go-v2/service/account v1.19.6 // indirect
+	github.com/aws/aws-sdk-go-v2/service/apigateway v1.25.8 // indirect
 	github.com/aws/aws-sdk-go-v2/service/apigatewayv2 v1.22.7 // indirect
+	github.com/aws/aws-sdk-go-v2/service/appsync v1.36.0 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.11.4 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.11.19 // indirect
 	github.com/aws/aws-sdk-go-v2/service/sso v1.22.6 // indirect
@@ -31,4 +34,5 @@ require (
 	github.com/tidwall/pretty v1.2.0 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/oauth2 v0.22.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -7,18 +7,71 @@ import (
 )
 
 type GeneralApi struct {
-	Name                string `json:"name"`
-	DisplayName         string `json:"displayName"`
-	Version             string `json:"version"`
-	Description         string `json:"description"`
-	OwnerEmail          string `json:"ownerEmail"`
-	OwnerName           string `json:"ownerName"`
-	DocumentationUrl    string `json:"documentationUrl"`
-	GatewayUrl          string `json:"gatewayUrl"`
-	BasePath            string `json:"basePath"`
-	PlatformId          string `json:"platformId"`
-	PlatformName        string `json:"platformName"`
-	PlatformResourceUri string `json:"platformResourceUri"`
+	Name                string                  `json:"name"`
+	DisplayName         string                  `json:"displayName"`
+	Version             string                  `json:"version"`
+	Description         string                  `json:"description"`
+	OwnerEmail          string                  `json:"ownerEmail"`
+	OwnerName           string                  `json:"ownerName"`
+	DocumentationUrl    string                  `json:"documentationUrl"`
+	GatewayUrl          string                  `json:"gatewayUrl"`
+	BasePath            string                  `json:"basePath"`
+	PlatformId          string                  `json:"platformId"`
+	PlatformName        string                  `json:"platformName"`
+	PlatformResourceUri string                  `json:"platformResourceUri"`
+	VersionName         string                  `json:"versionName,omitempty"`
+	Region              string                  `json:"region,omitempty"`
+	AccountId           string                  `json:"accountId,omitempty"`
+	Gateway             string                  `json:"gateway,omitempty"`
+	VersioningScheme    string                  `json:"versioningScheme,omitempty"`
+	VersionParameter    string                  `json:"versionParameter,omitempty"`
+	Protocol            string                  `json:"protocol,omitempty"`
+	SecuritySchemes     []GeneralSecurityScheme `json:"securitySchemes,omitempty"`
+	Backends            []GeneralBackend        `json:"backends,omitempty"`
+	Revisions           []GeneralRevision       `json:"revisions,omitempty"`
+}
+
+type GeneralRevision struct {
+	Revision    string `json:"revision"`
+	Description string `json:"description"`
+	IsCurrent   bool   `json:"isCurrent"`
+	CreatedDate string `json:"createdDate,omitempty"`
+	UpdatedDate string `json:"updatedDate,omitempty"`
+}
+
+type GeneralBackend struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Url          string   `json:"url"`
+	Method       string   `json:"method,omitempty"`
+	ConnectionId string   `json:"connectionId,omitempty"`
+	Routes       []string `json:"routes,omitempty"`
+}
+
+type GeneralSecurityScheme struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Issuer         string   `json:"issuer,omitempty"`
+	Audiences      []string `json:"audiences,omitempty"`
+	IdentitySource string   `json:"identitySource,omitempty"`
+}
+
+type GeneralProduct struct {
+	Name                string   `json:"name"`
+	DisplayName         string   `json:"displayName"`
+	Description         string   `json:"description"`
+	Apis                []string `json:"apis"`
+	QuotaLimit          int      `json:"quotaLimit,omitempty"`
+	QuotaInterval       int      `json:"quotaInterval,omitempty"`
+	QuotaTimeUnit       string   `json:"quotaTimeUnit,omitempty"`
+	RateLimit           float64  `json:"rateLimit,omitempty"`
+	BurstLimit          int      `json:"burstLimit,omitempty"`
+	Subscriptions       int      `json:"subscriptions,omitempty"`
+	SubscriptionsLimit  int      `json:"subscriptionsLimit,omitempty"`
+	ApprovalRequired    bool     `json:"approvalRequired,omitempty"`
+	PlatformId          string   `json:"platformId"`
+	PlatformName        string   `json:"platformName"`
+	PlatformResourceUri string   `json:"platformResourceUri"`
 }
 
 type PlatformStatus struct {
@@ -44,15 +97,32 @@ func main() {
 	apigeeCommand := cli.NewSubCommand("apigee", "'apis export', 'apis import', 'apis deploy', 'apis clean'...")
 	apigeeApisCommand := apigeeCommand.NewSubCommand("apis", "'apis export', 'apis import', 'apis deploy', 'apis clean'...")
 	apigeeApisCommand.NewSubCommandFunction("export", "Exports Apigee APIs from a given project.", apigeeExport)
+	apigeeApisCommand.NewSubCommandFunction("offramp", "Migrates exported Apigee API proxies out to general.", apigeeOfframp)
+	apigeeApisCommand.NewSubCommandFunction("onramp", "Generates pass-through Apigee proxy bundles from general APIs.", apigeeOnramp)
 	apigeeApisCommand.NewSubCommandFunction("import", "Imports APIs to an Apigee project.", apigeeImport)
 	apigeeApisCommand.NewSubCommandFunction("deploy", "Deploys APIs to an Apigee project and environment.", apigeeDeploy)
 	apigeeApisCommand.NewSubCommandFunction("clean", "Removes all of the Apigee APIs from a given project.", apigeeClean)
+	apigeeSharedFlowsCommand := apigeeCommand.NewSubCommand("sharedflows", "'sharedflows export', 'sharedflows import', 'sharedflows deploy', 'sharedflows clean'...")
+	apigeeSharedFlowsCommand.NewSubCommandFunction("export", "Exports Apigee shared flows from a given project.", apigeeSharedFlowsExport)
+	apigeeSharedFlowsCommand.NewSubCommandFunction("import", "Imports shared flows to an Apigee project, called shared flows first.", apigeeSharedFlowsImport)
+	apigeeSharedFlowsCommand.NewSubCommandFunction("deploy", "Deploys shared flows to an Apigee project and environment, called shared flows first.", apigeeSharedFlowsDeploy)
+	apigeeSharedFlowsCommand.NewSubCommandFunction("clean", "Removes all of the Apigee shared flows from a given project.", apigeeSharedFlowsClean)
 	apigeeTestCommand := apigeeCommand.NewSubCommand("test", "Local test commands.")
 	apigeeTestCommand.NewSubCommandFunction("init", "Initializes local test data for an environment.", initApigeeTest)
+	apigeeTestCommand.NewSubCommandFunction("apply", "Creates or updates the test developer, product and app of an environment, and prints the app key.", applyApigeeTest)
+	apigeeTestCommand.NewSubCommandFunction("teardown", "Removes the test developer, product and app of an environment.", teardownApigeeTest)
 	apigeeProductsCommand := apigeeCommand.NewSubCommand("products", "Functions for Apigee products.")
+	apigeeProductsCommand.NewSubCommandFunction("export", "Exports products from a given project.", apigeeProductsExport)
+	apigeeProductsCommand.NewSubCommandFunction("import", "Creates or updates exported or onramped products in a given project.", apigeeProductsImport)
+	apigeeProductsCommand.NewSubCommandFunction("onramp", "Onramps products from general to Apigee products.", apigeeProductsOnramp)
 	apigeeProductsCommand.NewSubCommandFunction("clean", "Removes all products from a given project.", apigeeProductsClean)
 	apigeeDevelopersCommand := apigeeCommand.NewSubCommand("developers", "Functions for Apigee developers.")
+	apigeeDevelopersCommand.NewSubCommandFunction("export", "Exports developers from a given project.", apigeeDevelopersExport)
+	apigeeDevelopersCommand.NewSubCommandFunction("import", "Creates or updates exported developers in a given project.", apigeeDevelopersImport)
 	apigeeDevelopersCommand.NewSubCommandFunction("clean", "Removes all developers and apps from a given project.", apigeeDevelopersClean)
+	apigeeAppsCommand := apigeeCommand.NewSubCommand("apps", "Functions for Apigee developer apps.")
+	apigeeAppsCommand.NewSubCommandFunction("export", "Exports developer apps, without their keys, from a given project.", apigeeAppsExport)
+	apigeeAppsCommand.NewSubCommandFunction("import", "Creates or updates exported developer apps in a given project, new apps get new keys.", apigeeAppsImport)
 	apigeeTestCommand.NewSubCommandFunction("init", "Initializes local test data for an environment.", initApigeeTest)
 
 	apiHubCommand := cli.NewSubCommand("apihub", "'apis export', 'apis import', 'apis onramp', 'apis clean'...")
@@ -67,14 +137,34 @@ func main() {
 	azureCommand.NewSubCommandFunction("export", "'apis export', 'apis offramp', 'apis cleanlocal'...", azureServiceExport)
 	azureApisCommand := azureCommand.NewSubCommand("apis", "Functions for Azure API Management API resources.")
 	azureApisCommand.NewSubCommandFunction("export", "Exports Azure API Management APIs.", azureExportMin)
+	azureApisCommand.NewSubCommandFunction("discover", "Discovers and exports the APIs of all API Management services in one or more subscriptions.", azureDiscoverMin)
 	azureApisCommand.NewSubCommandFunction("offramp", "Migrates Azure API Management APIs out to general.", azureOfframp)
+	azureApisCommand.NewSubCommandFunction("onramp", "Migrates general APIs into Azure API Management format.", azureOnramp)
+	azureApisCommand.NewSubCommandFunction("import", "Creates or updates onramped APIs in an Azure API Management service.", azureImport)
 	azureApisCommand.NewSubCommandFunction("cleanlocal", "Removes all exported Azure APIs from local storage.", azureCleanLocal)
+	azureProductsCommand := azureCommand.NewSubCommand("products", "Functions for Azure API Management products.")
+	azureProductsCommand.NewSubCommandFunction("export", "Exports Azure API Management products.", azureProductsExportMin)
+	azureProductsCommand.NewSubCommandFunction("offramp", "Migrates Azure API Management products out to general.", azureProductsOfframp)
+	azurePoliciesCommand := azureCommand.NewSubCommand("policies", "Functions for Azure API Management policies.")
+	azurePoliciesCommand.NewSubCommandFunction("translate", "Translates exported Azure API Management policies to Apigee policies.", azurePoliciesTranslate)
+
+	apiCenterCommand := cli.NewSubCommand("apicenter", "'apis export', 'apis offramp', 'apis onramp', 'apis import', 'apis cleanlocal'...")
+	apiCenterApisCommand := apiCenterCommand.NewSubCommand("apis", "'apis export', 'apis offramp', 'apis onramp', 'apis import', 'apis cleanlocal'...")
+	apiCenterApisCommand.NewSubCommandFunction("export", "Exports Azure API Center APIs with their versions, definitions and deployments.", apiCenterExportMin)
+	apiCenterApisCommand.NewSubCommandFunction("offramp", "Migrates exported Azure API Center APIs out to general.", apiCenterOfframp)
+	apiCenterApisCommand.NewSubCommandFunction("onramp", "Onramps APIs from general to Azure API Center.", apiCenterOnramp)
+	apiCenterApisCommand.NewSubCommandFunction("import", "Creates or updates onramped APIs, environments and deployments in Azure API Center.", apiCenterImport)
+	apiCenterApisCommand.NewSubCommandFunction("cleanlocal", "Removes all API Center APIs from local storage.", apiCenterCleanLocal)
 
 	awsCommand := cli.NewSubCommand("aws", "'apis export', 'apis offramp', 'apis cleanlocal'...")
 	awsApisCommand := awsCommand.NewSubCommand("apis", "'apis export', 'apis offramp', 'apis cleanlocal'...")
 	awsApisCommand.NewSubCommandFunction("export", "Exports AWS API Gateway APIs.", awsExportMin)
+	awsApisCommand.NewSubCommandFunction("discover", "Exports AWS API Gateway APIs across regions and accounts.", awsDiscoverMin)
 	awsApisCommand.NewSubCommandFunction("offramp", "Offramp AWS API Gateway APIs.", awsOfframp)
+	awsApisCommand.NewSubCommandFunction("import", "Imports general APIs with OpenAPI 3 specs as AWS API Gateway HTTP APIs.", awsImport)
 	awsApisCommand.NewSubCommandFunction("cleanlocal", "Removes all exported AWS APIs from local storage.", awsCleanLocal)
+	awsGraphqlCommand := awsCommand.NewSubCommand("graphql", "'graphql export'...")
+	awsGraphqlCommand.NewSubCommandFunction("export", "Exports AWS AppSync GraphQL APIs, offramped together with 'apis offramp'.", awsGraphqlExportMin)
 
 	err := cli.Run()
 
@@ -30,7 +30,7 @@ type ApimStatus struct {
 
 type ApimOfframpInput struct {
 	Body struct {
-		Offramp string `json:"offramp" enum:"azure,aws" doc:"The APIM platform to offramp the APIs from."`
+		Offramp string `json:"offramp" enum:"azure,aws,apicenter,apigee" doc:"The APIM platform to offramp the APIs from."`
 		OnlyNew bool   `json:"onlyNew" doc:"Default is false, only offramp new APIs. Set to false to offramp all APIs."`
 	}
 }
@@ -45,7 +45,7 @@ type ApimOfframpOutput struct {
 
 type ApimOnrampInput struct {
 	Body struct {
-		Onramp string `json:"onramp" enum:"apihub" doc:"The API platform to onramp the APIs to."`
+		Onramp string `json:"onramp" enum:"apihub,apicenter,azure,aws,apigee" doc:"The API platform to onramp the APIs to."`
 	}
 }
 
@@ -58,8 +58,8 @@ type ApimOnrampOutput struct {
 
 type ApintSyncInput struct {
 	Body struct {
-		Offramp string `json:"offramp" enum:"azure,aws" doc:"The APIM platform to offramp the APIs from."`
-		Onramp  string `json:"onramp" enum:"apihub" doc:"The APIM platform to onramp the APIs to."`
+		Offramp string `json:"offramp" enum:"azure,aws,apicenter,apigee" doc:"The APIM platform to offramp the APIs from."`
+		Onramp  string `json:"onramp" enum:"apihub,apicenter,azure,aws,apigee" doc:"The APIM platform to onramp the APIs to."`
 	}
 }
 
@@ -95,8 +95,8 @@ func webServerStart(flags *WebServerFlags) error {
 func apimStatus(ctx context.Context, input *struct{}) (*ApimStatus, error) {
 	var status ApimStatus
 	apigeeFlags := ApigeeFlags{Project: os.Getenv("APIGEE_PROJECT"), Region: os.Getenv("APIGEE_REGION")}
-	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME")}
-	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY")}
+	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME"), Subscriptions: os.Getenv("AZURE_SUBSCRIPTIONS")}
+	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY"), Regions: os.Getenv("AWS_REGIONS"), RoleArns: os.Getenv("AWS_ROLE_ARNS")}
 	status.Body.ApigeeStatus = apigeeStatus(&apigeeFlags)
 	status.Body.ApiHubStatus = apiHubStatus(&apigeeFlags)
 	status.Body.AzureStatus = azureStatus(&azureFlags)
@@ -108,20 +108,42 @@ func apimStatus(ctx context.Context, input *struct{}) (*ApimStatus, error) {
 func apimOfframp(ctx context.Context, input *ApimOfframpInput) (*ApimOfframpOutput, error) {
 	var result ApimOfframpOutput
 
-	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME")}
+	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME"), Revisions: os.Getenv("AZURE_REVISIONS") == "true", Counts: os.Getenv("AZURE_SUBSCRIPTION_COUNTS") == "true", Policies: os.Getenv("AZURE_POLICIES") == "true", Subscriptions: os.Getenv("AZURE_SUBSCRIPTIONS")}
 	azureFlags.OnlyNew = input.Body.OnlyNew
-	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY")}
+	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY"), Regions: os.Getenv("AWS_REGIONS"), RoleArns: os.Getenv("AWS_ROLE_ARNS")}
 	awsFlags.OnlyNew = input.Body.OnlyNew
+	apiCenterFlags := ApiCenterFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_APICENTER_NAME")}
+	apigeeFlags := ApigeeFlags{Project: os.Getenv("APIGEE_PROJECT"), Region: os.Getenv("APIGEE_REGION")}
 
 	if input.Body.Offramp == "azure" {
-		azureServiceExport(&azureFlags)
-		result.Body.Apis, _ = azureExport(&azureFlags)
+		if azureFlags.Subscriptions != "" {
+			result.Body.Apis, _ = azureDiscover(&azureFlags)
+		} else {
+			azureServiceExport(&azureFlags)
+			result.Body.Apis, _ = azureExport(&azureFlags)
+			azureProductsExport(&azureFlags)
+		}
 		azureOfframp(&azureFlags)
+		azureProductsOfframp(&azureFlags)
 		result.Body.Result = true
 	} else if input.Body.Offramp == "aws" {
-		result.Body.Apis, _ = awsExport(&awsFlags)
+		if awsFlags.Regions != "" || awsFlags.RoleArns != "" {
+			result.Body.Apis, _ = awsDiscover(&awsFlags)
+		} else {
+			result.Body.Apis, _ = awsExport(&awsFlags)
+			graphqlApis, _ := awsGraphqlExport(&awsFlags)
+			result.Body.Apis = append(result.Body.Apis, graphqlApis...)
+		}
 		awsOfframp(&awsFlags)
 		result.Body.Result = true
+	} else if input.Body.Offramp == "apicenter" {
+		result.Body.Apis, _ = apiCenterExport(&apiCenterFlags)
+		apiCenterOfframp(&apiCenterFlags)
+		result.Body.Result = true
+	} else if input.Body.Offramp == "apigee" {
+		apigeeExport(&apigeeFlags)
+		apigeeOfframp(&apigeeFlags)
+		result.Body.Result = true
 	}
 
 	if result.Body.Result {
@@ -137,11 +159,29 @@ func apimOfframp(ctx context.Context, input *ApimOfframpInput) (*ApimOfframpOutp
 func apimOnramp(ctx context.Context, input *ApimOnrampInput) (*ApimOnrampOutput, error) {
 	var result ApimOnrampOutput
 
-	apigeeFlags := ApigeeFlags{Project: os.Getenv("APIGEE_PROJECT"), Region: os.Getenv("APIGEE_REGION")}
+	apigeeFlags := ApigeeFlags{Project: os.Getenv("APIGEE_PROJECT"), Region: os.Getenv("APIGEE_REGION"), Environment: os.Getenv("APIGEE_ENVIRONMENT"), Security: os.Getenv("APIGEE_SECURITY")}
+	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME")}
+	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY")}
+	apiCenterFlags := ApiCenterFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_APICENTER_NAME")}
 
 	if input.Body.Onramp == "apihub" {
 		apiHubOnramp(&apigeeFlags)
 		apiHubImport(&apigeeFlags)
+	} else if input.Body.Onramp == "apicenter" {
+		apiCenterOnramp(&apiCenterFlags)
+		apiCenterImport(&apiCenterFlags)
+	} else if input.Body.Onramp == "azure" {
+		azureOnramp(&azureFlags)
+		azureImport(&azureFlags)
+	} else if input.Body.Onramp == "aws" {
+		awsImport(&awsFlags)
+	} else if input.Body.Onramp == "apigee" {
+		apigeeOnramp(&apigeeFlags)
+		apigeeSharedFlowsImport(&apigeeFlags)
+		apigeeImport(&apigeeFlags)
+		if apigeeFlags.Environment != "" {
+			apigeeDeploy(&apigeeFlags)
+		}
 	}
 
 	result.Body.Result = true
@@ -152,22 +192,55 @@ func apimOnramp(ctx context.Context, input *ApimOnrampInput) (*ApimOnrampOutput,
 func apintSync(ctx context.Context, input *ApintSyncInput) (*ApintSyncOutput, error) {
 	var result ApintSyncOutput
 
-	apigeeFlags := ApigeeFlags{Project: os.Getenv("APIGEE_PROJECT"), Region: os.Getenv("APIGEE_REGION")}
-	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME")}
-	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY")}
+	apigeeFlags := ApigeeFlags{Project: os.Getenv("APIGEE_PROJECT"), Region: os.Getenv("APIGEE_REGION"), Environment: os.Getenv("APIGEE_ENVIRONMENT"), Security: os.Getenv("APIGEE_SECURITY")}
+	azureFlags := AzureFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_SERVICE_NAME"), Revisions: os.Getenv("AZURE_REVISIONS") == "true", Counts: os.Getenv("AZURE_SUBSCRIPTION_COUNTS") == "true", Policies: os.Getenv("AZURE_POLICIES") == "true", Subscriptions: os.Getenv("AZURE_SUBSCRIPTIONS")}
+	awsFlags := AwsFlags{Region: os.Getenv("AWS_REGION"), AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), AccessSecret: os.Getenv("AWS_SECRET_ACCESS_KEY"), Regions: os.Getenv("AWS_REGIONS"), RoleArns: os.Getenv("AWS_ROLE_ARNS")}
+	apiCenterFlags := ApiCenterFlags{Subscription: os.Getenv("AZURE_SUBSCRIPTION_ID"), ResourceGroup: os.Getenv("AZURE_RESOURCE_GROUP"), ServiceName: os.Getenv("AZURE_APICENTER_NAME")}
 
 	if input.Body.Offramp == "azure" {
-		azureServiceExport(&azureFlags)
-		azureExport(&azureFlags)
+		if azureFlags.Subscriptions != "" {
+			azureDiscover(&azureFlags)
+		} else {
+			azureServiceExport(&azureFlags)
+			azureExport(&azureFlags)
+			azureProductsExport(&azureFlags)
+		}
 		azureOfframp(&azureFlags)
+		azureProductsOfframp(&azureFlags)
 	} else if input.Body.Offramp == "aws" {
-		awsExport(&awsFlags)
+		if awsFlags.Regions != "" || awsFlags.RoleArns != "" {
+			awsDiscover(&awsFlags)
+		} else {
+			awsExport(&awsFlags)
+			awsGraphqlExport(&awsFlags)
+		}
 		awsOfframp(&awsFlags)
+	} else if input.Body.Offramp == "apicenter" {
+		apiCenterExport(&apiCenterFlags)
+		apiCenterOfframp(&apiCenterFlags)
+	} else if input.Body.Offramp == "apigee" {
+		apigeeExport(&apigeeFlags)
+		apigeeOfframp(&apigeeFlags)
 	}
 
 	if input.Body.Onramp == "apihub" {
 		apiHubOnramp(&apigeeFlags)
 		apiHubImport(&apigeeFlags)
+	} else if input.Body.Onramp == "apicenter" {
+		apiCenterOnramp(&apiCenterFlags)
+		apiCenterImport(&apiCenterFlags)
+	} else if input.Body.Onramp == "azure" {
+		azureOnramp(&azureFlags)
+		azureImport(&azureFlags)
+	} else if input.Body.Onramp == "aws" {
+		awsImport(&awsFlags)
+	} else if input.Body.Onramp == "apigee" {
+		apigeeOnramp(&apigeeFlags)
+		apigeeSharedFlowsImport(&apigeeFlags)
+		apigeeImport(&apigeeFlags)
+		if apigeeFlags.Environment != "" {
+			apigeeDeploy(&apigeeFlags)
+		}
 	}
 
 	result.Body.Result = true